@@ -0,0 +1,146 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+)
+
+// identifierRequest identifica o cliente nas rotas /admin/limits
+type identifierRequest struct {
+	Identifier    string `json:"identifier,omitempty"`
+	KeyIdentifier string `json:"key_identifier,omitempty"`
+	LimitType     string `json:"limit_type"`
+}
+
+type statusResponse struct {
+	LimitType            string `json:"limit_type"`
+	KeyIdentifier        string `json:"key_identifier"`
+	Rule                 string `json:"rule"`
+	Count                int64  `json:"count"`
+	Limit                int64  `json:"limit"`
+	Remaining            int64  `json:"remaining"`
+	BlockDurationSeconds int64  `json:"block_duration_seconds"`
+	Blocked              bool   `json:"blocked"`
+	BlockTTLSeconds      int64  `json:"block_ttl_seconds"`
+}
+
+// identifierFlags são as opções dos comandos que operam sobre um cliente
+type identifierFlags struct {
+	*flag.FlagSet
+	limitType *string
+	key       *bool
+}
+
+func newIdentifierFlags(name string) *identifierFlags {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	return &identifierFlags{
+		FlagSet:   flags,
+		limitType: flags.String("type", "token", "tipo de limite: ip ou token"),
+		key:       flags.Bool("key", false, "o identificador é o das chaves (ex: hash de um token listado em list ou top)"),
+	}
+}
+
+// parse lê as opções e o identificador, que deve ser o último argumento
+func (f *identifierFlags) parse(args []string) (*identifierRequest, error) {
+	if err := f.Parse(args); err != nil {
+		return nil, err
+	}
+	if f.NArg() != 1 {
+		return nil, fmt.Errorf("%s: expected one identifier after the options", f.Name())
+	}
+
+	req := &identifierRequest{LimitType: *f.limitType}
+	if *f.key {
+		req.KeyIdentifier = f.Arg(0)
+	} else {
+		req.Identifier = f.Arg(0)
+	}
+	return req, nil
+}
+
+// runInspect mostra o contador, a cota restante e o bloqueio de um cliente
+// (POST /admin/limits/status)
+func runInspect(client *adminClient, args []string, out io.Writer) error {
+	flags := newIdentifierFlags("inspect")
+	tier := flags.String("tier", "", "tier do cliente, para o limite do plano")
+	req, err := flags.parse(args)
+	if err != nil {
+		return err
+	}
+
+	body := struct {
+		*identifierRequest
+		Tier string `json:"tier,omitempty"`
+	}{req, *tier}
+	return postStatus(client, "/admin/limits/status", body, out)
+}
+
+// runBlock bloqueia um cliente pela duração informada (POST /admin/limits/block)
+func runBlock(client *adminClient, args []string, out io.Writer) error {
+	flags := newIdentifierFlags("block")
+	duration := flags.Duration("duration", 0, "duração do bloqueio (ex: 30m, 1h)")
+	reason := flags.String("reason", "", "motivo registrado na auditoria")
+	req, err := flags.parse(args)
+	if err != nil {
+		return err
+	}
+	if *duration < time.Second {
+		return fmt.Errorf("block: -duration of at least 1s is required")
+	}
+
+	body := struct {
+		*identifierRequest
+		DurationSeconds int64  `json:"duration_seconds"`
+		Reason          string `json:"reason,omitempty"`
+	}{req, int64(duration.Seconds()), *reason}
+	return postStatus(client, "/admin/limits/block", body, out)
+}
+
+// runUnblock remove o bloqueio de um cliente (POST /admin/limits/unblock)
+func runUnblock(client *adminClient, args []string, out io.Writer) error {
+	req, err := newIdentifierFlags("unblock").parse(args)
+	if err != nil {
+		return err
+	}
+	return postStatus(client, "/admin/limits/unblock", req, out)
+}
+
+// runReset zera o contador de um cliente (POST /admin/limits/reset)
+func runReset(client *adminClient, args []string, out io.Writer) error {
+	req, err := newIdentifierFlags("reset").parse(args)
+	if err != nil {
+		return err
+	}
+	return postStatus(client, "/admin/limits/reset", req, out)
+}
+
+func postStatus(client *adminClient, path string, body any, out io.Writer) error {
+	var status statusResponse
+	if err := client.do("POST", path, body, &status); err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	printStatus(w, &status)
+	return w.Flush()
+}
+
+// printStatus escreve o estado do cliente, um campo por linha
+func printStatus(w io.Writer, status *statusResponse) {
+	fmt.Fprintf(w, "Tipo:\t%s\n", status.LimitType)
+	fmt.Fprintf(w, "Identificador:\t%s\n", status.KeyIdentifier)
+	fmt.Fprintf(w, "Regra:\t%s\n", status.Rule)
+	fmt.Fprintf(w, "Limite:\t%d req/s, bloqueio de %ds ao exceder\n", status.Limit, status.BlockDurationSeconds)
+	fmt.Fprintf(w, "Requisições na janela:\t%d (restam %d)\n", status.Count, status.Remaining)
+	switch {
+	case !status.Blocked:
+		fmt.Fprintf(w, "Bloqueado:\tnão\n")
+	case status.BlockTTLSeconds > 0:
+		fmt.Fprintf(w, "Bloqueado:\tsim, expira em %ds\n", status.BlockTTLSeconds)
+	default:
+		fmt.Fprintf(w, "Bloqueado:\tsim, sem expiração\n")
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// headerFlag acumula as opções -H no formato "Nome: valor"
+type headerFlag map[string]string
+
+func (h headerFlag) String() string {
+	return fmt.Sprint(map[string]string(h))
+}
+
+func (h headerFlag) Set(value string) error {
+	name, headerValue, ok := strings.Cut(value, ":")
+	if !ok || strings.TrimSpace(name) == "" {
+		return fmt.Errorf("invalid header %q: expected \"Name: value\"", value)
+	}
+	h[strings.TrimSpace(name)] = strings.TrimSpace(headerValue)
+	return nil
+}
+
+type explainResponse struct {
+	Result       string `json:"result"`
+	ClientIP     string `json:"client_ip"`
+	TokenPresent bool   `json:"token_present"`
+	Access       string `json:"access"`
+	Tier         string `json:"tier"`
+	statusResponse
+}
+
+// runLimits mostra o limite efetivo de uma requisição descrita pelo IP,
+// headers e query (POST /admin/limits/explain), sem contabilizá-la
+func runLimits(client *adminClient, args []string, out io.Writer) error {
+	headers := headerFlag{}
+	flags := flag.NewFlagSet("limits", flag.ContinueOnError)
+	ip := flags.String("ip", "", "endereço IP do cliente")
+	query := flags.String("query", "", "query string da requisição (ex: api_key=abc123)")
+	flags.Var(headers, "H", "header da requisição no formato \"Nome: valor\" (repetível)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	body := map[string]any{
+		"ip":      *ip,
+		"headers": headers,
+		"query":   *query,
+	}
+	var response explainResponse
+	if err := client.do("POST", "/admin/limits/explain", body, &response); err != nil {
+		return err
+	}
+
+	token := "ausente"
+	if response.TokenPresent {
+		token = "presente"
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "IP do cliente:\t%s\n", response.ClientIP)
+	fmt.Fprintf(w, "Token:\t%s\n", token)
+	fmt.Fprintf(w, "Listas de acesso:\t%s\n", response.Access)
+	switch response.Result {
+	case "allowlisted":
+		fmt.Fprintf(w, "Resultado:\tliberada pela allowlist, sem limite\n")
+	case "denylisted":
+		fmt.Fprintf(w, "Resultado:\trejeitada pela denylist (403)\n")
+	case "unknown_token":
+		fmt.Fprintf(w, "Resultado:\trejeitada por token desconhecido (401)\n")
+	default:
+		if response.Tier != "" {
+			fmt.Fprintf(w, "Tier:\t%s\n", response.Tier)
+		}
+		printStatus(w, &response.statusResponse)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+)
+
+type blocksResponse struct {
+	Blocks []struct {
+		LimitType     string `json:"limit_type"`
+		KeyIdentifier string `json:"key_identifier"`
+		TTLSeconds    int64  `json:"ttl_seconds"`
+	} `json:"blocks"`
+	NextCursor uint64 `json:"next_cursor"`
+}
+
+// runList lista os bloqueios ativos, percorrendo todas as páginas de
+// GET /admin/limits/blocks
+func runList(client *adminClient, args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("list", flag.ContinueOnError)
+	limitType := flags.String("type", "", "lista apenas o tipo de limite informado: ip ou token")
+	count := flags.Int("count", 100, "chaves examinadas por página")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	var blocks blocksResponse
+	var cursor uint64
+	for {
+		query := url.Values{}
+		query.Set("cursor", strconv.FormatUint(cursor, 10))
+		query.Set("count", strconv.Itoa(*count))
+
+		var page blocksResponse
+		if err := client.do("GET", "/admin/limits/blocks?"+query.Encode(), nil, &page); err != nil {
+			return err
+		}
+		for _, block := range page.Blocks {
+			if *limitType == "" || block.LimitType == *limitType {
+				blocks.Blocks = append(blocks.Blocks, block)
+			}
+		}
+
+		cursor = page.NextCursor
+		if cursor == 0 {
+			break
+		}
+	}
+
+	sort.Slice(blocks.Blocks, func(i, j int) bool {
+		a, b := blocks.Blocks[i], blocks.Blocks[j]
+		if a.LimitType != b.LimitType {
+			return a.LimitType < b.LimitType
+		}
+		return a.KeyIdentifier < b.KeyIdentifier
+	})
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TIPO\tIDENTIFICADOR\tEXPIRA EM")
+	for _, block := range blocks.Blocks {
+		expires := "sem expiração"
+		if block.TTLSeconds > 0 {
+			expires = fmt.Sprintf("%ds", block.TTLSeconds)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", block.LimitType, block.KeyIdentifier, expires)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "\n%d bloqueio(s) ativo(s)\n", len(blocks.Blocks))
+	return nil
+}
@@ -0,0 +1,73 @@
+// Comando ratelimitctl opera o rate limiter pela API de administração do
+// servidor (ver internal/admin) e valida a configuração do servidor.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+// command é um subcomando do ratelimitctl
+type command struct {
+	name        string
+	description string
+	run         func(client *adminClient, args []string, out io.Writer) error
+}
+
+var commands = []command{
+	{name: "list", description: "Lista os bloqueios ativos", run: runList},
+	{name: "inspect", description: "Mostra o contador, a cota restante e o bloqueio de um cliente", run: runInspect},
+	{name: "block", description: "Bloqueia um cliente pela duração informada", run: runBlock},
+	{name: "unblock", description: "Remove o bloqueio de um cliente", run: runUnblock},
+	{name: "reset", description: "Zera o contador de um cliente", run: runReset},
+	{name: "limits", description: "Mostra o limite efetivo de uma requisição (IP, headers e query)", run: runLimits},
+	{name: "top", description: "Lista os maiores consumidores dos últimos minutos", run: runTop},
+	{name: "validate", description: "Valida a configuração do servidor e os arquivos referenciados", run: runValidate},
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "ratelimitctl:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("ratelimitctl", flag.ContinueOnError)
+	server := flags.String("server", getEnv("RATELIMITCTL_SERVER", "http://localhost:8080"), "URL do servidor do rate limiter")
+	token := flags.String("token", os.Getenv("ADMIN_API_TOKEN"), "token da API de administração")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Uso: ratelimitctl [opções] <comando> [argumentos]\n\nComandos:\n")
+		for _, cmd := range commands {
+			fmt.Fprintf(flags.Output(), "  %-10s %s\n", cmd.name, cmd.description)
+		}
+		fmt.Fprintf(flags.Output(), "\nOpções:\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return fmt.Errorf("missing command")
+	}
+
+	name := flags.Arg(0)
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd.run(newAdminClient(*server, *token), flags.Args()[1:], out)
+		}
+	}
+	flags.Usage()
+	return fmt.Errorf("unknown command: %s", name)
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/m4rcelotoledo/rate-limiter/internal/admin"
+	"github.com/m4rcelotoledo/rate-limiter/pkg/heavyhitters"
+	"github.com/m4rcelotoledo/rate-limiter/pkg/limiter"
+	"github.com/m4rcelotoledo/rate-limiter/pkg/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+const testToken = "admin-secret"
+
+// newTestServer inicia a API de administração com um limiter em memória
+func newTestServer(t *testing.T, opts ...limiter.Option) (*httptest.Server, *limiter.RateLimiter) {
+	t.Helper()
+
+	memoryStorage := storage.NewMemoryStorage()
+	t.Cleanup(func() { memoryStorage.Close() })
+
+	rateLimiter := limiter.New(memoryStorage, opts...)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	admin.NewHandler(testToken, rateLimiter).RegisterRoutes(router)
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server, rateLimiter
+}
+
+func runCommand(t *testing.T, server *httptest.Server, args ...string) (string, error) {
+	t.Helper()
+
+	var out bytes.Buffer
+	err := run(append([]string{"-server", server.URL, "-token", testToken}, args...), &out)
+	return out.String(), err
+}
+
+func TestRun_Top(t *testing.T) {
+	server, rateLimiter := newTestServer(t, limiter.WithIPLimit(5, 60), limiter.WithHeavyHitters(heavyhitters.NewMemoryTracker(60)))
+
+	ctx := context.Background()
+	for i := 0; i < 7; i++ {
+		rateLimiter.CheckLimit(ctx, "192.168.1.1", "ip")
+	}
+	rateLimiter.CheckLimit(ctx, "192.168.1.2", "ip")
+
+	output, err := runCommand(t, server, "top", "-type", "ip", "-minutes", "10")
+	if err != nil {
+		t.Fatalf("top error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) != 5 || !strings.Contains(lines[0], "limite de 5 req/s") {
+		t.Fatalf("unexpected output:\n%s", output)
+	}
+	if fields := strings.Fields(lines[3]); strings.Join(fields, " ") != "192.168.1.1 7 2 6" {
+		t.Errorf("first consumer = %q, expected 192.168.1.1 with 7 requests, 2 denied and peak 6", lines[3])
+	}
+}
+
+func TestRun_Errors(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	if _, err := runCommand(t, server); err == nil {
+		t.Error("run without command should return an error")
+	}
+	if _, err := runCommand(t, server, "unknown"); err == nil {
+		t.Error("run with unknown command should return an error")
+	}
+
+	_, err := runCommand(t, server, "top")
+	if err == nil || !strings.Contains(err.Error(), "heavy hitters tracking is not enabled") {
+		t.Errorf("top without tracker error = %v, expected the server error message", err)
+	}
+
+	var out bytes.Buffer
+	err = run([]string{"-server", server.URL, "-token", "wrong", "top"}, &out)
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("top with wrong token error = %v, expected status 401", err)
+	}
+}
+
+func TestRun_ManageLimits(t *testing.T) {
+	server, rateLimiter := newTestServer(t, limiter.WithTokenLimit(2, 60), limiter.WithTokenHashSecret("hash-secret"))
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		rateLimiter.CheckLimit(ctx, "client-key", "token")
+	}
+	keyIdentifier := rateLimiter.KeyIdentifier("client-key", "token")
+
+	output, err := runCommand(t, server, "inspect", "client-key")
+	if err != nil {
+		t.Fatalf("inspect error = %v", err)
+	}
+	if !strings.Contains(output, keyIdentifier) || !strings.Contains(output, "sim, expira em 60s") {
+		t.Errorf("inspect output should show the blocked hashed token:\n%s", output)
+	}
+
+	output, err = runCommand(t, server, "block", "-type", "ip", "-duration", "10m", "-reason", "abuse", "192.168.1.1")
+	if err != nil {
+		t.Fatalf("block error = %v", err)
+	}
+	if !strings.Contains(output, "sim, expira em 600s") {
+		t.Errorf("block output should show the block expiration:\n%s", output)
+	}
+
+	output, err = runCommand(t, server, "list")
+	if err != nil {
+		t.Fatalf("list error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) != 5 || strings.Join(strings.Fields(lines[1]), " ") != "ip 192.168.1.1 600s" || !strings.Contains(lines[2], keyIdentifier) {
+		t.Errorf("unexpected list output:\n%s", output)
+	}
+
+	output, err = runCommand(t, server, "list", "-type", "ip")
+	if err != nil || !strings.Contains(output, "1 bloqueio(s) ativo(s)") {
+		t.Errorf("list -type ip = %q, %v, expected only the ip block", output, err)
+	}
+
+	for _, args := range [][]string{{"unblock", "-key", keyIdentifier}, {"reset", "-key", keyIdentifier}} {
+		output, err = runCommand(t, server, args...)
+		if err != nil {
+			t.Fatalf("%s error = %v", args[0], err)
+		}
+		if !strings.Contains(output, "Bloqueado:") || !strings.Contains(output, "não") {
+			t.Errorf("%s output should show the token unblocked:\n%s", args[0], output)
+		}
+	}
+	if result, _ := rateLimiter.CheckLimit(ctx, "client-key", "token"); !result.Allowed {
+		t.Error("token should be allowed after unblock and reset")
+	}
+
+	invalid := [][]string{
+		{"inspect"},
+		{"inspect", "client-key", "-type", "ip"},
+		{"block", "client-key"},
+		{"unblock", "-type", "user", "client-key"},
+	}
+	for _, args := range invalid {
+		if _, err := runCommand(t, server, args...); err == nil {
+			t.Errorf("%v should return an error", args)
+		}
+	}
+}
+
+func TestRun_Limits(t *testing.T) {
+	server, rateLimiter := newTestServer(t, limiter.WithIPLimit(5, 60), limiter.WithTokenLimit(50, 120))
+
+	ctx := context.Background()
+	rateLimiter.CheckLimit(ctx, "203.0.113.7", "ip")
+
+	output, err := runCommand(t, server, "limits", "-ip", "203.0.113.7")
+	if err != nil {
+		t.Fatalf("limits error = %v", err)
+	}
+	for _, expected := range []string{"ausente", "5 req/s, bloqueio de 60s", "1 (restam 4)"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("limits output should contain %q:\n%s", expected, output)
+		}
+	}
+
+	output, err = runCommand(t, server, "limits", "-ip", "203.0.113.7", "-H", "API_KEY: abc123")
+	if err != nil {
+		t.Fatalf("limits with token error = %v", err)
+	}
+	if !strings.Contains(output, "presente") || !strings.Contains(output, "50 req/s, bloqueio de 120s") {
+		t.Errorf("limits output should show the token limit:\n%s", output)
+	}
+
+	if _, err := runCommand(t, server, "limits", "-H", "API_KEY"); err == nil {
+		t.Error("limits with an invalid header should return an error")
+	}
+}
+
+// writeEnv grava o arquivo de variáveis e as remove do ambiente no fim do
+// teste, já que o carregamento as define no processo
+func writeEnv(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "test.env")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		for _, line := range strings.Split(content, "\n") {
+			if name, _, ok := strings.Cut(line, "="); ok {
+				os.Unsetenv(name)
+			}
+		}
+	})
+	return path
+}
+
+func TestRun_Validate(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		var out bytes.Buffer
+		if err := run([]string{"validate", "-env", writeEnv(t, "RATE_LIMIT_IP_REQUESTS_PER_SECOND=8\nRATE_LIMIT_TIERS=gold:1000:60\nRATE_LIMIT_TOKEN_HASH_SECRET=hash-secret\n")}, &out); err != nil {
+			t.Fatalf("validate error = %v", err)
+		}
+		output := out.String()
+		if !strings.Contains(output, "Configuração válida") || !strings.Contains(output, "token:gold") {
+			t.Errorf("unexpected validate output:\n%s", output)
+		}
+		if fields := strings.Fields(strings.Split(output, "\n")[3]); strings.Join(fields, " ") != "ip 8 300s" {
+			t.Errorf("ip limit line = %v, expected ip 8 300s", fields)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		var out bytes.Buffer
+		err := run([]string{"validate", "-env", writeEnv(t, "RATE_LIMIT_IP_REQUESTS_PER_SECOND=0\nLOG_FORMAT=xml\nACCESS_LIST_FILE=/nonexistent/access.json\nRATE_LIMIT_REJECTION_STATUS=42\nRATE_LIMIT_REJECTION_RULE_STATUS=ip=200\n")}, &out)
+		if err == nil {
+			t.Fatal("validate with an invalid config should return an error")
+		}
+		for _, expected := range []string{"RATE_LIMIT_IP_REQUESTS_PER_SECOND", "invalid log format", "access.json", "RATE_LIMIT_REJECTION_STATUS", `status for rule "ip"`, "RATE_LIMIT_TOKEN_HASH_SECRET is required"} {
+			if !strings.Contains(out.String(), expected) {
+				t.Errorf("validate output should report %q:\n%s", expected, out.String())
+			}
+		}
+	})
+
+	t.Run("authorization with jwt", func(t *testing.T) {
+		var out bytes.Buffer
+		env := writeEnv(t, "RATE_LIMIT_TOKEN_HEADERS=Authorization\nJWT_HMAC_SECRET=jwt-secret\nRATE_LIMIT_TOKEN_HASH_SECRET=hash-secret\n")
+		if err := run([]string{"validate", "-env", env}, &out); err != nil {
+			t.Errorf("validate with Authorization and a JWT verifier error = %v", err)
+		}
+		if strings.Contains(out.String(), "Avisos") {
+			t.Errorf("validate with Authorization and a JWT verifier should not warn:\n%s", out.String())
+		}
+	})
+
+	t.Run("authorization without validation", func(t *testing.T) {
+		var out bytes.Buffer
+		env := writeEnv(t, "RATE_LIMIT_TOKEN_HEADERS=Authorization\nRATE_LIMIT_TOKEN_HASH_SECRET=hash-secret\n")
+		if err := run([]string{"validate", "-env", env}, &out); err != nil {
+			t.Fatalf("validate with Authorization without validation error = %v", err)
+		}
+		if !strings.Contains(out.String(), "Avisos:") || !strings.Contains(out.String(), "Authorization is read without") {
+			t.Errorf("validate output should warn about Authorization:\n%s", out.String())
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		if err := run([]string{"validate", "-env", "/nonexistent.env"}, io.Discard); err == nil {
+			t.Error("validate with a missing file should return an error")
+		}
+	})
+}
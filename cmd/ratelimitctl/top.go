@@ -0,0 +1,51 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"text/tabwriter"
+)
+
+type topResponse struct {
+	LimitType     string `json:"limit_type"`
+	WindowMinutes int    `json:"window_minutes"`
+	Limit         int64  `json:"limit"`
+	Consumers     []struct {
+		KeyIdentifier string `json:"key_identifier"`
+		Requests      int64  `json:"requests"`
+		Denied        int64  `json:"denied"`
+		PeakRate      int64  `json:"peak_rate"`
+	} `json:"consumers"`
+}
+
+// runTop lista os maiores consumidores (GET /admin/limits/top)
+func runTop(client *adminClient, args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("top", flag.ContinueOnError)
+	limitType := flags.String("type", "token", "tipo de limite: ip ou token")
+	minutes := flags.Int("minutes", 5, "janela do relatório, em minutos")
+	n := flags.Int("n", 10, "número de consumidores")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	query := url.Values{}
+	query.Set("limit_type", *limitType)
+	query.Set("minutes", strconv.Itoa(*minutes))
+	query.Set("n", strconv.Itoa(*n))
+
+	var response topResponse
+	if err := client.do("GET", "/admin/limits/top?"+query.Encode(), nil, &response); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Maiores consumidores (%s) nos últimos %d minutos, limite de %d req/s\n\n", response.LimitType, response.WindowMinutes, response.Limit)
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "IDENTIFICADOR\tREQUISIÇÕES\tNEGADAS\tPICO/S")
+	for _, consumer := range response.Consumers {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\n", consumer.KeyIdentifier, consumer.Requests, consumer.Denied, consumer.PeakRate)
+	}
+	return w.Flush()
+}
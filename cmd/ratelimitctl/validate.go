@@ -0,0 +1,69 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/m4rcelotoledo/rate-limiter/internal/config"
+)
+
+// runValidate valida a configuração do servidor (variáveis de ambiente e
+// arquivo .env) e os arquivos referenciados por ela, e mostra os limites
+// configurados. Não usa a API de administração.
+func runValidate(_ *adminClient, args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("validate", flag.ContinueOnError)
+	envFile := flags.String("env", "", "arquivo de variáveis (padrão: .env do diretório atual, se existir)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	var cfg *config.Config
+	var err error
+	if *envFile != "" {
+		cfg, err = config.LoadFile(*envFile)
+	} else {
+		cfg, err = config.Load()
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintln(out, "Configuração inválida:")
+		for _, line := range strings.Split(err.Error(), "\n") {
+			fmt.Fprintf(out, "  - %s\n", line)
+		}
+		return fmt.Errorf("invalid configuration")
+	}
+
+	fmt.Fprintf(out, "Configuração válida\n\n")
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "REGRA\tREQ/S\tBLOQUEIO")
+	fmt.Fprintf(w, "ip\t%d\t%ds\n", cfg.RateLimitIPRequestsPerSecond, cfg.RateLimitIPBlockDurationSeconds)
+	fmt.Fprintf(w, "token\t%d\t%ds\n", cfg.RateLimitTokenRequestsPerSecond, cfg.RateLimitTokenBlockDurationSeconds)
+
+	tiers := make([]string, 0, len(cfg.RateLimitTiers))
+	for name := range cfg.RateLimitTiers {
+		tiers = append(tiers, name)
+	}
+	sort.Strings(tiers)
+	for _, name := range tiers {
+		tier := cfg.RateLimitTiers[name]
+		fmt.Fprintf(w, "token:%s\t%d\t%ds\n", name, tier.RequestsPerSecond, tier.BlockDurationSeconds)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if warnings := cfg.Warnings(); len(warnings) > 0 {
+		fmt.Fprintln(out, "\nAvisos:")
+		for _, warning := range warnings {
+			fmt.Fprintf(out, "  - %s\n", warning)
+		}
+	}
+	return nil
+}
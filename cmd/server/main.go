@@ -2,19 +2,36 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/m4rcelotoledo/rate-limiter/internal/admin"
 	"github.com/m4rcelotoledo/rate-limiter/internal/config"
-	"github.com/m4rcelotoledo/rate-limiter/internal/limiter"
-	"github.com/m4rcelotoledo/rate-limiter/internal/middleware"
-	"github.com/m4rcelotoledo/rate-limiter/internal/storage"
+	"github.com/m4rcelotoledo/rate-limiter/pkg/access"
+	"github.com/m4rcelotoledo/rate-limiter/pkg/audit"
+	"github.com/m4rcelotoledo/rate-limiter/pkg/heavyhitters"
+	"github.com/m4rcelotoledo/rate-limiter/pkg/keystore"
+	"github.com/m4rcelotoledo/rate-limiter/pkg/limiter"
+	"github.com/m4rcelotoledo/rate-limiter/pkg/metrics"
+	"github.com/m4rcelotoledo/rate-limiter/pkg/middleware"
+	"github.com/m4rcelotoledo/rate-limiter/pkg/storage"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 )
 
 func main() {
@@ -23,6 +40,45 @@ func main() {
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Invalid config: %v", err)
+	}
+
+	// Logs estruturados: log.Printf também passa pelo handler do slog
+	logger, err := newLogger(cfg)
+	if err != nil {
+		log.Fatalf("Failed to configure logging: %v", err)
+	}
+	slog.SetDefault(logger)
+	for _, warning := range cfg.Warnings() {
+		logger.Warn(warning)
+	}
+
+	// Métricas Prometheus do limiter e do storage, se habilitadas
+	var rateLimiterMetrics *metrics.Metrics
+	registry := prometheus.NewRegistry()
+	if cfg.MetricsEnabled {
+		registry.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+		rateLimiterMetrics = metrics.New(registry,
+			metrics.WithBlockCountInterval(time.Duration(cfg.MetricsBlockCountIntervalSeconds)*time.Second),
+		)
+	}
+
+	// Configura o tracing OpenTelemetry, se habilitado
+	tracerProvider, err := newTracerProvider(context.Background(), cfg)
+	if err != nil {
+		log.Fatalf("Failed to configure tracing: %v", err)
+	}
+	if tracerProvider != nil {
+		otel.SetTracerProvider(tracerProvider)
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := tracerProvider.Shutdown(ctx); err != nil {
+				log.Printf("Failed to flush traces: %v", err)
+			}
+		}()
+	}
 
 	// Inicializa o storage Redis
 	redisStorage, err := storage.NewRedisStorage(
@@ -30,6 +86,7 @@ func main() {
 		cfg.RedisPort,
 		cfg.RedisPassword,
 		cfg.RedisDB,
+		storage.WithMetrics(rateLimiterMetrics),
 	)
 	if err != nil {
 		log.Fatalf("Failed to connect to Redis: %v", err)
@@ -37,21 +94,120 @@ func main() {
 	defer redisStorage.Close()
 
 	// Configura o rate limiter
-	limiterConfig := &limiter.Config{
-		IPRequestsPerSecond:       cfg.RateLimitIPRequestsPerSecond,
-		IPBlockDurationSeconds:    cfg.RateLimitIPBlockDurationSeconds,
-		TokenRequestsPerSecond:    cfg.RateLimitTokenRequestsPerSecond,
-		TokenBlockDurationSeconds: cfg.RateLimitTokenBlockDurationSeconds,
+	limiterConfig := cfg.LimiterConfig()
+
+	// Configura a auditoria de bloqueios, se habilitada
+	auditEmitter, err := newAuditEmitter(cfg, redisStorage, logger, rateLimiterMetrics)
+	if err != nil {
+		log.Fatalf("Failed to configure audit sink: %v", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := auditEmitter.Close(ctx); err != nil {
+			log.Printf("Failed to flush audit events: %v", err)
+		}
+	}()
+
+	// Carrega as listas de acesso (allowlist/denylist)
+	accessList := access.NewList()
+	if cfg.AccessListFile != "" {
+		accessList, err = access.LoadFile(cfg.AccessListFile)
+		if err != nil {
+			log.Fatalf("Failed to load access list: %v", err)
+		}
 	}
 
-	rateLimiter := limiter.NewRateLimiter(redisStorage, limiterConfig)
+	limiterOptions := []limiter.Option{
+		limiter.WithAccessList(accessList),
+		limiter.WithMetrics(rateLimiterMetrics),
+		limiter.WithLogger(logger),
+		limiter.WithAllowedLogSampleRate(cfg.LogAllowedSampleRate),
+		limiter.WithPlainIPLogging(cfg.LogPlainIPs),
+		limiter.WithAuditEmitter(auditEmitter),
+	}
+
+	// Configura a validação de tokens, se habilitada
+	keyStore, err := newKeyStore(cfg, redisStorage)
+	if err != nil {
+		log.Fatalf("Failed to configure key store: %v", err)
+	}
+	if keyStore != nil {
+		limiterOptions = append(limiterOptions, limiter.WithKeyStore(keyStore))
+	}
+
+	// Configura a identificação por JWT, se habilitada
+	jwtVerifier, err := cfg.JWTVerifier()
+	if err != nil {
+		log.Fatalf("Failed to configure JWT verifier: %v", err)
+	}
+	if jwtVerifier != nil {
+		limiterOptions = append(limiterOptions, limiter.WithTokenVerifier(jwtVerifier))
+	}
+
+	// Acompanha os maiores consumidores em sorted sets do Redis, se habilitado
+	if cfg.HeavyHittersEnabled {
+		keyPrefix := ""
+		if cfg.RateLimitKeyNamespace != "" {
+			keyPrefix = cfg.RateLimitKeyNamespace + ":"
+		}
+		tracker := heavyhitters.NewRedisTracker(redisStorage.Client(), cfg.HeavyHittersRetentionMinutes,
+			heavyhitters.WithKeyPrefix(keyPrefix),
+			heavyhitters.WithRedisCapacity(cfg.HeavyHittersCapacity),
+			heavyhitters.WithLogger(logger),
+		)
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := tracker.Close(ctx); err != nil {
+				log.Printf("Failed to flush heavy hitters: %v", err)
+			}
+		}()
+		limiterOptions = append(limiterOptions, limiter.WithHeavyHitters(tracker))
+	}
+
+	// Com tracing habilitado, cada operação do storage gera um span
+	var limiterStorage limiter.StorageStrategy = redisStorage
+	if tracerProvider != nil {
+		limiterStorage = storage.NewTracedStorage(redisStorage, tracerProvider)
+	}
+
+	rateLimiter := limiter.NewRateLimiter(limiterStorage, limiterConfig, limiterOptions...)
 
 	// Configura o servidor Gin
 	gin.SetMode(gin.ReleaseMode)
-	router := gin.Default()
+	router := gin.New()
+	router.Use(gin.Recovery(), requestLogger(logger))
+
+	// Expõe as métricas antes do middleware, para que o scraping não seja limitado
+	if cfg.MetricsEnabled {
+		router.GET(cfg.MetricsPath, gin.WrapH(promhttp.HandlerFor(registry, promhttp.HandlerOpts{})))
+	}
+
+	// Adiciona a API de administração, se habilitada. Como as métricas, ela é
+	// registrada antes do middleware: o token de administração a protege, e
+	// bloqueios ou a denylist não podem impedir o operador de desfazê-los
+	var adminHandler *admin.Handler
+	if cfg.AdminAPIToken != "" {
+		adminHandler = admin.NewHandler(cfg.AdminAPIToken, rateLimiter)
+		adminHandler.RegisterRoutes(router)
+	} else {
+		log.Println("Admin API disabled: ADMIN_API_TOKEN is not set")
+	}
 
 	// Adiciona o middleware de rate limiting
-	router.Use(middleware.RateLimiterMiddleware(rateLimiter))
+	headerMode, err := middleware.ParseHeaderMode(cfg.RateLimitHeaders)
+	if err != nil {
+		log.Fatalf("Failed to configure rate limit headers: %v", err)
+	}
+	rejectionHandler, err := cfg.RejectionHandler()
+	if err != nil {
+		log.Fatalf("Failed to configure rejection response: %v", err)
+	}
+	router.Use(middleware.RateLimiterMiddleware(rateLimiter,
+		middleware.WithHeaders(headerMode),
+		middleware.WithRejectionHandler(rejectionHandler),
+	))
 
 	// Servir arquivos estáticos
 	router.Static("/static", "./static")
@@ -90,6 +246,9 @@ func main() {
 		Addr:    ":" + cfg.ServerPort,
 		Handler: router,
 	}
+	if adminHandler != nil {
+		srv.RegisterOnShutdown(adminHandler.Close)
+	}
 
 	// Canal para receber sinais de interrupção
 	quit := make(chan os.Signal, 1)
@@ -117,3 +276,121 @@ func main() {
 
 	log.Println("Server exited")
 }
+
+// newLogger cria o logger slog com o formato (LOG_FORMAT: json ou text) e o
+// nível (LOG_LEVEL: debug, info, warn ou error) configurados
+func newLogger(cfg *config.Config) (*slog.Logger, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(cfg.LogLevel)); err != nil {
+		return nil, fmt.Errorf("invalid log level: %s", cfg.LogLevel)
+	}
+
+	handlerOptions := &slog.HandlerOptions{Level: level}
+	switch cfg.LogFormat {
+	case "json":
+		return slog.New(slog.NewJSONHandler(os.Stdout, handlerOptions)), nil
+	case "text":
+		return slog.New(slog.NewTextHandler(os.Stdout, handlerOptions)), nil
+	default:
+		return nil, fmt.Errorf("invalid log format: %s", cfg.LogFormat)
+	}
+}
+
+// requestLogger substitui o logger padrão do Gin por um log estruturado de
+// cada requisição
+func requestLogger(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		logger.LogAttrs(c.Request.Context(), slog.LevelDebug, "request",
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", c.Writer.Status()),
+			slog.Duration("latency", time.Since(start)),
+			slog.String("client_ip", c.ClientIP()),
+		)
+	}
+}
+
+// newAuditEmitter cria o emitter de auditoria com o destino indicado em
+// AUDIT_SINK: "stdout", "file", "redis" (Redis Stream) ou "webhook". Retorna
+// nil quando a auditoria está desabilitada.
+func newAuditEmitter(cfg *config.Config, redisStorage *storage.RedisStorage, logger *slog.Logger, rateLimiterMetrics *metrics.Metrics) (*audit.Emitter, error) {
+	var sink audit.Sink
+	switch cfg.AuditSink {
+	case "":
+		return nil, nil
+	case "stdout":
+		sink = audit.NewWriterSink(os.Stdout)
+	case "file":
+		fileSink, err := audit.NewFileSink(cfg.AuditFile)
+		if err != nil {
+			return nil, err
+		}
+		sink = fileSink
+	case "redis":
+		sink = audit.NewRedisStreamSink(redisStorage.Client(), cfg.AuditRedisStream, int64(cfg.AuditRedisStreamMaxLen))
+	case "webhook":
+		if cfg.AuditWebhookURL == "" {
+			return nil, fmt.Errorf("AUDIT_WEBHOOK_URL is required for the webhook audit sink")
+		}
+		sink = audit.NewWebhookSink(cfg.AuditWebhookURL, 5*time.Second)
+	default:
+		return nil, fmt.Errorf("invalid audit sink: %s", cfg.AuditSink)
+	}
+
+	return audit.NewEmitter(sink,
+		audit.WithQueueSize(cfg.AuditQueueSize),
+		audit.WithLogger(logger),
+		audit.WithMetrics(rateLimiterMetrics),
+	), nil
+}
+
+// newTracerProvider cria o tracer provider indicado em TRACING_EXPORTER:
+// "otlp" (OTLP/HTTP, configurado pelas variáveis OTEL_EXPORTER_OTLP_*) ou
+// "stdout". Retorna nil quando o tracing está desabilitado.
+func newTracerProvider(ctx context.Context, cfg *config.Config) (*sdktrace.TracerProvider, error) {
+	var exporter sdktrace.SpanExporter
+	var err error
+
+	switch cfg.TracingExporter {
+	case "":
+		return nil, nil
+	case "otlp":
+		exporter, err = otlptracehttp.New(ctx)
+	case "stdout":
+		exporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	default:
+		return nil, fmt.Errorf("invalid tracing exporter: %s", cfg.TracingExporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s exporter: %w", cfg.TracingExporter, err)
+	}
+
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.TracingSampleRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", cfg.TracingServiceName))),
+	), nil
+}
+
+// newKeyStore cria o key store indicado em KEY_STORE_TYPE. Retorna nil quando
+// a validação de tokens está desabilitada.
+func newKeyStore(cfg *config.Config, redisStorage *storage.RedisStorage) (keystore.KeyStore, error) {
+	switch cfg.KeyStoreType {
+	case "":
+		return nil, nil
+	case "file":
+		return keystore.LoadStaticFile(cfg.KeyStoreFile)
+	case "redis":
+		return keystore.NewRedisSetStore(redisStorage.Client(), cfg.KeyStoreRedisSet), nil
+	case "hmac":
+		if cfg.KeyStoreHMACSecret == "" {
+			return nil, fmt.Errorf("KEY_STORE_HMAC_SECRET is required for the hmac key store")
+		}
+		return keystore.NewHMACStore([]byte(cfg.KeyStoreHMACSecret)), nil
+	default:
+		return nil, fmt.Errorf("invalid key store type: %s", cfg.KeyStoreType)
+	}
+}
@@ -4,12 +4,13 @@ import (
 	"context"
 	"log"
 	"net/http"
+	"path"
 	"time"
 
 	"github.com/m4rcelotoledo/rate-limiter/internal/config"
-	"github.com/m4rcelotoledo/rate-limiter/internal/limiter"
-	"github.com/m4rcelotoledo/rate-limiter/internal/middleware"
-	"github.com/m4rcelotoledo/rate-limiter/internal/storage"
+	"github.com/m4rcelotoledo/rate-limiter/pkg/limiter"
+	"github.com/m4rcelotoledo/rate-limiter/pkg/middleware"
+	"github.com/m4rcelotoledo/rate-limiter/pkg/storage"
 
 	"github.com/gin-gonic/gin"
 )
@@ -40,6 +41,10 @@ func main() {
 		IPBlockDurationSeconds:    cfg.RateLimitIPBlockDurationSeconds,
 		TokenRequestsPerSecond:    cfg.RateLimitTokenRequestsPerSecond,
 		TokenBlockDurationSeconds: cfg.RateLimitTokenBlockDurationSeconds,
+		IPv4PrefixLength:          cfg.RateLimitIPv4PrefixLength,
+		IPv6PrefixLength:          cfg.RateLimitIPv6PrefixLength,
+		TokenHeaders:              cfg.RateLimitTokenHeaders,
+		TokenQueryParam:           cfg.RateLimitTokenQueryParam,
 	}
 
 	rateLimiter := limiter.NewRateLimiter(redisStorage, limiterConfig)
@@ -147,6 +152,22 @@ func (c *CustomStorage) Delete(ctx context.Context, key string) error {
 	return nil
 }
 
+func (c *CustomStorage) TTL(ctx context.Context, key string) (time.Duration, error) {
+	// Este exemplo não controla expiração
+	return 0, nil
+}
+
+func (c *CustomStorage) Scan(ctx context.Context, cursor uint64, match string, count int64) ([]string, uint64, error) {
+	// Este exemplo retorna todas as chaves em uma única chamada
+	var keys []string
+	for key := range c.data {
+		if matched, _ := path.Match(match, key); matched {
+			keys = append(keys, key)
+		}
+	}
+	return keys, 0, nil
+}
+
 func (c *CustomStorage) Close() error {
 	return nil
 }
@@ -155,14 +176,10 @@ func (c *CustomStorage) Close() error {
 func customStorageExample() {
 	customStorage := NewCustomStorage()
 
-	limiterConfig := &limiter.Config{
-		IPRequestsPerSecond:       5,
-		IPBlockDurationSeconds:    60,
-		TokenRequestsPerSecond:    10,
-		TokenBlockDurationSeconds: 120,
-	}
-
-	rateLimiter := limiter.NewRateLimiter(customStorage, limiterConfig)
+	rateLimiter := limiter.New(customStorage,
+		limiter.WithIPLimit(5, 60),
+		limiter.WithTokenLimit(10, 120),
+	)
 
 	// Use o rate limiter normalmente
 	ctx := context.Background()
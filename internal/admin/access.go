@@ -0,0 +1,101 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/m4rcelotoledo/rate-limiter/pkg/access"
+	"github.com/m4rcelotoledo/rate-limiter/pkg/audit"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) listAccess(c *gin.Context) {
+	list, ok := h.accessList(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, list.Entries())
+}
+
+func (h *Handler) addAccess(c *gin.Context) {
+	h.updateAccess(c, (*access.List).Add, audit.BlockEvent)
+}
+
+func (h *Handler) removeAccess(c *gin.Context) {
+	h.updateAccess(c, (*access.List).Remove, audit.UnblockEvent)
+}
+
+// updateAccess aplica a alteração na lista indicada em :list ("allow" ou
+// "deny") com os IPs/tokens do corpo da requisição. Alterações na denylist são
+// registradas na auditoria como bloqueios ou desbloqueios manuais.
+func (h *Handler) updateAccess(c *gin.Context, update func(*access.List, access.Decision, access.Rules) error, eventType audit.EventType) {
+	list, ok := h.accessList(c)
+	if !ok {
+		return
+	}
+
+	decision, err := access.ParseDecision(c.Param("list"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	var rules access.Rules
+	if err := c.ShouldBindJSON(&rules); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid request body",
+		})
+		return
+	}
+
+	if err := update(list, decision, rules); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if decision == access.Deny {
+		h.auditDenyList(c, rules, eventType)
+	}
+
+	c.JSON(http.StatusOK, list.Entries())
+}
+
+// auditDenyList registra um evento por IP/CIDR e token alterado na denylist
+func (h *Handler) auditDenyList(c *gin.Context, rules access.Rules, eventType audit.EventType) {
+	emitter := h.rateLimiter.AuditEmitter()
+	ctx := c.Request.Context()
+
+	for _, ip := range rules.IPs {
+		emitter.Emit(ctx, audit.Event{
+			Type:       eventType,
+			Source:     audit.SourceAdmin,
+			LimitType:  "ip",
+			Identifier: ip,
+			Reason:     "denylist",
+		})
+	}
+	for _, token := range rules.Tokens {
+		emitter.Emit(ctx, audit.Event{
+			Type:       eventType,
+			Source:     audit.SourceAdmin,
+			LimitType:  "token",
+			Identifier: h.rateLimiter.RedactedIdentifier(token, "token"),
+			Reason:     "denylist",
+		})
+	}
+}
+
+func (h *Handler) accessList(c *gin.Context) (*access.List, bool) {
+	list := h.rateLimiter.AccessList()
+	if list == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "access lists are not enabled",
+		})
+		return nil, false
+	}
+	return list, true
+}
@@ -0,0 +1,72 @@
+package admin
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"sync"
+
+	"github.com/m4rcelotoledo/rate-limiter/pkg/limiter"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TokenHeader é o header que deve conter o token da API de administração
+const TokenHeader = "X-Admin-Token"
+
+// Handler expõe a API HTTP de administração do rate limiter
+type Handler struct {
+	token       string
+	rateLimiter *limiter.RateLimiter
+	// done encerra os streams de eventos abertos (ver Close)
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func NewHandler(token string, rateLimiter *limiter.RateLimiter) *Handler {
+	return &Handler{
+		token:       token,
+		rateLimiter: rateLimiter,
+		done:        make(chan struct{}),
+	}
+}
+
+// Close encerra os streams de eventos abertos, para que não atrasem o
+// desligamento do servidor (ver http.Server.RegisterOnShutdown)
+func (h *Handler) Close() {
+	h.closeOnce.Do(func() {
+		close(h.done)
+	})
+}
+
+// RegisterRoutes registra as rotas de administração sob /admin, todas
+// protegidas pelo token de administração
+func (h *Handler) RegisterRoutes(router gin.IRouter) {
+	group := router.Group("/admin", h.authenticate)
+
+	group.GET("/access", h.listAccess)
+	group.POST("/access/:list", h.addAccess)
+	group.DELETE("/access/:list", h.removeAccess)
+
+	group.POST("/lookup", h.lookup)
+	group.GET("/events", h.streamEvents)
+
+	group.GET("/limits/blocks", h.listBlocks)
+	group.GET("/limits/top", h.topConsumers)
+	group.POST("/limits/status", h.limitStatus)
+	group.POST("/limits/block", h.blockLimit)
+	group.POST("/limits/unblock", h.unblockLimit)
+	group.POST("/limits/reset", h.resetLimit)
+	group.POST("/limits/explain", h.explainLimits)
+}
+
+func (h *Handler) authenticate(c *gin.Context) {
+	token := c.GetHeader(TokenHeader)
+	if h.token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) != 1 {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "unauthorized",
+		})
+		c.Abort()
+		return
+	}
+	c.Next()
+}
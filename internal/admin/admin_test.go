@@ -0,0 +1,506 @@
+package admin
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/m4rcelotoledo/rate-limiter/pkg/access"
+	"github.com/m4rcelotoledo/rate-limiter/pkg/audit"
+	"github.com/m4rcelotoledo/rate-limiter/pkg/heavyhitters"
+	"github.com/m4rcelotoledo/rate-limiter/pkg/limiter"
+	"github.com/m4rcelotoledo/rate-limiter/pkg/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+const testToken = "admin-secret"
+
+func newTestRouter(rateLimiter *limiter.RateLimiter) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	NewHandler(testToken, rateLimiter).RegisterRoutes(router)
+	return router
+}
+
+func doRequest(router *gin.Engine, method, path, token, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set(TokenHeader, token)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestHandler_Authentication(t *testing.T) {
+	rateLimiter := limiter.NewRateLimiter(storage.NewMemoryStorage(), &limiter.Config{}, limiter.WithAccessList(access.NewList()))
+	router := newTestRouter(rateLimiter)
+
+	tests := []struct {
+		name     string
+		token    string
+		expected int
+	}{
+		{name: "Missing token", token: "", expected: http.StatusUnauthorized},
+		{name: "Wrong token", token: "wrong", expected: http.StatusUnauthorized},
+		{name: "Valid token", token: testToken, expected: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := doRequest(router, http.MethodGet, "/admin/access", tt.token, "")
+			if w.Code != tt.expected {
+				t.Errorf("GET /admin/access = %d, expected %d", w.Code, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHandler_ManageAccessList(t *testing.T) {
+	list := access.NewList()
+	rateLimiter := limiter.NewRateLimiter(storage.NewMemoryStorage(), &limiter.Config{}, limiter.WithAccessList(list))
+	router := newTestRouter(rateLimiter)
+
+	w := doRequest(router, http.MethodPost, "/admin/access/deny", testToken, `{"ips": ["203.0.113.0/24"], "tokens": ["bad-key"]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("POST /admin/access/deny = %d, body %s", w.Code, w.Body.String())
+	}
+	if result := rateLimiter.CheckAccess("203.0.113.50", ""); result != access.Deny {
+		t.Errorf("CheckAccess() = %v, expected %v", result, access.Deny)
+	}
+
+	w = doRequest(router, http.MethodDelete, "/admin/access/deny", testToken, `{"ips": ["203.0.113.0/24"]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("DELETE /admin/access/deny = %d, body %s", w.Code, w.Body.String())
+	}
+	if result := rateLimiter.CheckAccess("203.0.113.50", ""); result != access.None {
+		t.Errorf("CheckAccess() = %v, expected %v", result, access.None)
+	}
+	if result := rateLimiter.CheckAccess("", "bad-key"); result != access.Deny {
+		t.Errorf("CheckAccess() = %v, expected %v", result, access.Deny)
+	}
+
+	w = doRequest(router, http.MethodPost, "/admin/access/allow", testToken, `{"ips": ["invalid"]}`)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("POST with invalid IP = %d, expected %d", w.Code, http.StatusBadRequest)
+	}
+
+	w = doRequest(router, http.MethodPost, "/admin/access/unknown", testToken, `{}`)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("POST to unknown list = %d, expected %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestHandler_Lookup(t *testing.T) {
+	rateLimiter := limiter.NewRateLimiter(storage.NewMemoryStorage(), &limiter.Config{TokenHashSecret: "hash-secret"})
+	router := newTestRouter(rateLimiter)
+
+	w := doRequest(router, http.MethodPost, "/admin/lookup", testToken, `{"identifier": "live-api-key"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("POST /admin/lookup = %d, body %s", w.Code, w.Body.String())
+	}
+
+	var response lookupResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	expectedKey, expectedBlockKey := rateLimiter.StorageKeys("live-api-key", "token")
+	if response.RateLimitKey != expectedKey || response.BlockKey != expectedBlockKey {
+		t.Errorf("lookup keys = %q/%q, expected %q/%q", response.RateLimitKey, response.BlockKey, expectedKey, expectedBlockKey)
+	}
+	if strings.Contains(response.RateLimitKey, "live-api-key") {
+		t.Errorf("lookup key %q should not contain the raw token", response.RateLimitKey)
+	}
+
+	w = doRequest(router, http.MethodPost, "/admin/lookup", testToken, `{"identifier": "x", "limit_type": "user"}`)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("POST /admin/lookup with invalid limit type = %d, expected %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestHandler_AuditDenyList(t *testing.T) {
+	var output bytes.Buffer
+	emitter := audit.NewEmitter(audit.NewWriterSink(&output))
+	rateLimiter := limiter.NewRateLimiter(storage.NewMemoryStorage(), &limiter.Config{TokenHashSecret: "hash-secret"},
+		limiter.WithAccessList(access.NewList()),
+		limiter.WithAuditEmitter(emitter),
+	)
+	router := newTestRouter(rateLimiter)
+
+	doRequest(router, http.MethodPost, "/admin/access/deny", testToken, `{"ips": ["203.0.113.0/24"], "tokens": ["leaked-key"]}`)
+	doRequest(router, http.MethodDelete, "/admin/access/deny", testToken, `{"ips": ["203.0.113.0/24"]}`)
+	doRequest(router, http.MethodPost, "/admin/access/allow", testToken, `{"ips": ["10.0.0.0/8"]}`)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := emitter.Close(ctx); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	var events []audit.Event
+	for _, line := range strings.Split(strings.TrimSpace(output.String()), "\n") {
+		var event audit.Event
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("invalid audit event %q: %v", line, err)
+		}
+		events = append(events, event)
+	}
+
+	expected := []struct {
+		eventType  audit.EventType
+		identifier string
+	}{
+		{eventType: audit.BlockEvent, identifier: "203.0.113.0/24"},
+		{eventType: audit.BlockEvent, identifier: rateLimiter.RedactedIdentifier("leaked-key", "token")},
+		{eventType: audit.UnblockEvent, identifier: "203.0.113.0/24"},
+	}
+	if len(events) != len(expected) {
+		t.Fatalf("got %d audit events, expected %d: %s", len(events), len(expected), output.String())
+	}
+	for i, e := range expected {
+		if events[i].Type != e.eventType || events[i].Identifier != e.identifier || events[i].Source != audit.SourceAdmin {
+			t.Errorf("event %d = %+v, expected %s of %s from admin", i, events[i], e.eventType, e.identifier)
+		}
+	}
+}
+
+func TestHandler_ManageLimits(t *testing.T) {
+	var output bytes.Buffer
+	emitter := audit.NewEmitter(audit.NewWriterSink(&output))
+	rateLimiter := limiter.New(storage.NewMemoryStorage(), limiter.WithTokenLimit(5, 60), limiter.WithAuditEmitter(emitter))
+	router := newTestRouter(rateLimiter)
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		rateLimiter.CheckLimit(ctx, "client-key", "token")
+	}
+
+	decode := func(w *httptest.ResponseRecorder) statusResponse {
+		t.Helper()
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, body %s", w.Code, w.Body.String())
+		}
+		var response statusResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return response
+	}
+
+	status := decode(doRequest(router, http.MethodPost, "/admin/limits/status", testToken, `{"identifier": "client-key"}`))
+	if status.Count != 3 || status.Remaining != 2 || status.Blocked {
+		t.Errorf("status = %+v, expected count 3, remaining 2 and not blocked", status)
+	}
+
+	status = decode(doRequest(router, http.MethodPost, "/admin/limits/reset", testToken, `{"identifier": "client-key"}`))
+	if status.Count != 0 || status.Remaining != 5 {
+		t.Errorf("status after reset = %+v, expected count 0 and remaining 5", status)
+	}
+
+	status = decode(doRequest(router, http.MethodPost, "/admin/limits/block", testToken, `{"identifier": "client-key", "duration_seconds": 120, "reason": "abuse report"}`))
+	if !status.Blocked || status.BlockTTLSeconds != 120 {
+		t.Errorf("status after block = %+v, expected blocked for 120s", status)
+	}
+
+	status = decode(doRequest(router, http.MethodPost, "/admin/limits/unblock", testToken, `{"identifier": "client-key"}`))
+	if status.Blocked {
+		t.Errorf("status after unblock = %+v, expected not blocked", status)
+	}
+
+	invalid := []string{
+		`{"identifier": "client-key", "limit_type": "user"}`,
+		`{"limit_type": "ip"}`,
+	}
+	for _, body := range invalid {
+		if w := doRequest(router, http.MethodPost, "/admin/limits/status", testToken, body); w.Code != http.StatusBadRequest {
+			t.Errorf("POST /admin/limits/status %s = %d, expected %d", body, w.Code, http.StatusBadRequest)
+		}
+	}
+	if w := doRequest(router, http.MethodPost, "/admin/limits/block", testToken, `{"identifier": "client-key"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("POST /admin/limits/block without duration = %d, expected %d", w.Code, http.StatusBadRequest)
+	}
+
+	closeCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if err := emitter.Close(closeCtx); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if strings.Contains(output.String(), "client-key") {
+		t.Errorf("audit events should not contain the raw token: %s", output.String())
+	}
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], `"type":"block"`) || !strings.Contains(lines[0], "abuse report") || !strings.Contains(lines[1], `"type":"unblock"`) {
+		t.Errorf("audit events = %s, expected a block and an unblock", output.String())
+	}
+}
+
+func TestHandler_ListBlocks(t *testing.T) {
+	memoryStorage := storage.NewMemoryStorage()
+	defer memoryStorage.Close()
+	rateLimiter := limiter.New(memoryStorage)
+	router := newTestRouter(rateLimiter)
+
+	ctx := context.Background()
+	rateLimiter.Block(ctx, "192.168.1.1", "ip", time.Minute)
+	rateLimiter.Block(ctx, "api-key", "token", 2*time.Minute)
+
+	w := doRequest(router, http.MethodGet, "/admin/limits/blocks", testToken, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /admin/limits/blocks = %d, body %s", w.Code, w.Body.String())
+	}
+
+	var response blocksResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(response.Blocks) != 2 || response.NextCursor != 0 {
+		t.Errorf("blocks = %+v, expected 2 blocks in a single page", response)
+	}
+
+	for _, query := range []string{"?cursor=abc", "?count=0", "?cursor=4295967296"} {
+		if w := doRequest(router, http.MethodGet, "/admin/limits/blocks"+query, testToken, ""); w.Code != http.StatusBadRequest {
+			t.Errorf("GET /admin/limits/blocks%s = %d, expected %d", query, w.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestHandler_TopConsumers(t *testing.T) {
+	rateLimiter := limiter.New(storage.NewMemoryStorage(), limiter.WithIPLimit(5, 60), limiter.WithHeavyHitters(heavyhitters.NewMemoryTracker(60)))
+	router := newTestRouter(rateLimiter)
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		rateLimiter.CheckLimit(ctx, "192.168.1.1", "ip")
+	}
+	rateLimiter.CheckLimit(ctx, "192.168.1.2", "ip")
+
+	w := doRequest(router, http.MethodGet, "/admin/limits/top?limit_type=ip&minutes=10&n=1", testToken, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /admin/limits/top = %d, body %s", w.Code, w.Body.String())
+	}
+
+	var response topResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	expected := consumerEntry{KeyIdentifier: "192.168.1.1", Requests: 3, PeakRate: 3}
+	if response.Limit != 5 || response.WindowMinutes != 10 || len(response.Consumers) != 1 || response.Consumers[0] != expected {
+		t.Errorf("top = %+v, expected limit 5 and %+v", response, expected)
+	}
+
+	for _, query := range []string{"?limit_type=user", "?minutes=0", "?n=x"} {
+		if w := doRequest(router, http.MethodGet, "/admin/limits/top"+query, testToken, ""); w.Code != http.StatusBadRequest {
+			t.Errorf("GET /admin/limits/top%s = %d, expected %d", query, w.Code, http.StatusBadRequest)
+		}
+	}
+
+	router = newTestRouter(limiter.New(storage.NewMemoryStorage()))
+	if w := doRequest(router, http.MethodGet, "/admin/limits/top", testToken, ""); w.Code != http.StatusNotFound {
+		t.Errorf("GET /admin/limits/top without tracker = %d, expected %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestHandler_ManageLimitsByKeyIdentifier(t *testing.T) {
+	rateLimiter := limiter.New(storage.NewMemoryStorage(), limiter.WithTokenLimit(1, 60), limiter.WithTokenHashSecret("hash-secret"))
+	router := newTestRouter(rateLimiter)
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		rateLimiter.CheckLimit(ctx, "client-key", "token")
+	}
+	keyIdentifier := rateLimiter.KeyIdentifier("client-key", "token")
+
+	w := doRequest(router, http.MethodPost, "/admin/limits/unblock", testToken, `{"key_identifier": "`+keyIdentifier+`"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("POST /admin/limits/unblock = %d, body %s", w.Code, w.Body.String())
+	}
+	var response statusResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if response.Blocked || response.KeyIdentifier != keyIdentifier {
+		t.Errorf("status after unblock = %+v, expected %s not blocked", response, keyIdentifier)
+	}
+
+	invalid := []string{
+		`{"identifier": "client-key", "key_identifier": "` + keyIdentifier + `"}`,
+		`{}`,
+	}
+	for _, body := range invalid {
+		if w := doRequest(router, http.MethodPost, "/admin/limits/reset", testToken, body); w.Code != http.StatusBadRequest {
+			t.Errorf("POST /admin/limits/reset %s = %d, expected %d", body, w.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestHandler_Events(t *testing.T) {
+	rateLimiter := limiter.New(storage.NewMemoryStorage(), limiter.WithIPLimit(2, 60), limiter.WithHeavyHitters(heavyhitters.NewMemoryTracker(60)))
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		rateLimiter.CheckLimit(ctx, "192.168.1.1", "ip")
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handler := NewHandler(testToken, rateLimiter)
+	handler.RegisterRoutes(router)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	if w := doRequest(router, http.MethodGet, "/admin/events?interval=0", testToken, ""); w.Code != http.StatusBadRequest {
+		t.Errorf("GET /admin/events?interval=0 = %d, expected %d", w.Code, http.StatusBadRequest)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/admin/events", nil)
+	req.Header.Set(TokenHeader, testToken)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET /admin/events error = %v", err)
+	}
+	defer resp.Body.Close()
+	if contentType := resp.Header.Get("Content-Type"); resp.StatusCode != http.StatusOK || contentType != "text/event-stream" {
+		t.Fatalf("GET /admin/events = %d (%s), expected an event stream", resp.StatusCode, contentType)
+	}
+
+	// O primeiro snapshot é enviado imediatamente
+	scanner := bufio.NewScanner(resp.Body)
+	var event string
+	var snapshot dashboardSnapshot
+	for scanner.Scan() {
+		line := scanner.Text()
+		if value, ok := strings.CutPrefix(line, "event:"); ok {
+			event = value
+		}
+		if value, ok := strings.CutPrefix(line, "data:"); ok {
+			if err := json.Unmarshal([]byte(value), &snapshot); err != nil {
+				t.Fatalf("failed to decode snapshot: %v", err)
+			}
+			break
+		}
+	}
+	if event != "snapshot" {
+		t.Fatalf("event = %q, expected snapshot", event)
+	}
+
+	if len(snapshot.Blocks) != 1 || snapshot.Blocks[0].KeyIdentifier != "192.168.1.1" || snapshot.BlocksTruncated {
+		t.Errorf("blocks = %+v, expected the block of 192.168.1.1", snapshot.Blocks)
+	}
+	if snapshot.Limits["ip"] != 2 {
+		t.Errorf("limits = %+v, expected ip limit 2", snapshot.Limits)
+	}
+	if _, ok := snapshot.Rates["token"]; !ok {
+		t.Errorf("rates = %+v, expected ip and token rates", snapshot.Rates)
+	}
+	expected := consumerEntry{KeyIdentifier: "192.168.1.1", Requests: 3, Denied: 1, PeakRate: 3}
+	if top := snapshot.Top["ip"]; len(top) != 1 || top[0] != expected {
+		t.Errorf("top = %+v, expected %+v", snapshot.Top, expected)
+	}
+
+	// Close encerra o stream
+	handler.Close()
+	done := make(chan struct{})
+	go func() {
+		io.Copy(io.Discard, resp.Body)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Error("stream should end after Close()")
+	}
+}
+
+func TestHandler_ExplainLimits(t *testing.T) {
+	accessList := access.NewList()
+	accessList.Add(access.Deny, access.Rules{IPs: []string{"203.0.113.0/24"}})
+	rateLimiter := limiter.New(storage.NewMemoryStorage(),
+		limiter.WithIPLimit(5, 60),
+		limiter.WithTokenLimit(50, 120),
+		limiter.WithTier("gold", limiter.Tier{RequestsPerSecond: 1000, BlockDurationSeconds: 30}),
+		limiter.WithTokenVerifier(mockVerifier{}),
+		limiter.WithAccessList(accessList),
+		limiter.WithTrustedProxies(netip.MustParsePrefix("10.0.0.0/8")),
+	)
+	router := newTestRouter(rateLimiter)
+	rateLimiter.CheckLimit(context.Background(), "192.168.1.1", "ip")
+
+	tests := []struct {
+		name     string
+		body     string
+		expected map[string]any
+	}{
+		{
+			name:     "ip limit",
+			body:     `{"ip": "192.168.1.1"}`,
+			expected: map[string]any{"result": "limited", "limit_type": "ip", "limit": 5.0, "count": 1.0, "block_duration_seconds": 60.0},
+		},
+		{
+			name:     "forwarded ip",
+			body:     `{"ip": "10.0.0.1", "headers": {"X-Forwarded-For": "192.168.1.1"}}`,
+			expected: map[string]any{"client_ip": "192.168.1.1", "count": 1.0},
+		},
+		{
+			name:     "forwarded ip from untrusted client",
+			body:     `{"ip": "198.51.100.7", "headers": {"X-Forwarded-For": "192.168.1.1"}}`,
+			expected: map[string]any{"client_ip": "198.51.100.7", "count": 0.0},
+		},
+		{
+			name:     "token tier",
+			body:     `{"ip": "192.168.1.1", "query": "", "headers": {"API_KEY": "gold-token"}}`,
+			expected: map[string]any{"result": "limited", "token_present": true, "tier": "gold", "rule": "token:gold", "limit": 1000.0},
+		},
+		{
+			name:     "denylisted",
+			body:     `{"ip": "203.0.113.7"}`,
+			expected: map[string]any{"result": "denylisted", "access": "deny"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := doRequest(router, http.MethodPost, "/admin/limits/explain", testToken, tt.body)
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, body %s", w.Code, w.Body.String())
+			}
+			var response map[string]any
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			for key, value := range tt.expected {
+				if response[key] != value {
+					t.Errorf("%s = %v, expected %v (response %s)", key, response[key], value, w.Body.String())
+				}
+			}
+		})
+	}
+
+	if w := doRequest(router, http.MethodPost, "/admin/limits/explain", testToken, `{}`); w.Code != http.StatusBadRequest {
+		t.Errorf("explain without ip = %d, expected %d", w.Code, http.StatusBadRequest)
+	}
+	if w := doRequest(router, http.MethodPost, "/admin/limits/explain", testToken, `{"ip": "192.168.1.1", "query": "api_key=%zz"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("explain with malformed query = %d, expected %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+// mockVerifier aceita tokens "<tier>-token", com o tier no prefixo
+type mockVerifier struct{}
+
+func (mockVerifier) Verify(ctx context.Context, token string) (string, string, error) {
+	tier, ok := strings.CutSuffix(token, "-token")
+	if !ok {
+		return "", "", errors.New("invalid token")
+	}
+	return token, tier, nil
+}
@@ -0,0 +1,170 @@
+package admin
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/m4rcelotoledo/rate-limiter/pkg/limiter"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// defaultEventsInterval é o intervalo padrão entre os snapshots do dashboard
+	defaultEventsInterval = 2 * time.Second
+	// maxSnapshotBlocks é o número máximo de bloqueios enviados por snapshot
+	maxSnapshotBlocks = 100
+	// snapshotTopWindow e snapshotTopSize definem o relatório de maiores
+	// consumidores enviado em cada snapshot
+	snapshotTopWindow = 5 * time.Minute
+	snapshotTopSize   = 10
+)
+
+// decisionRate é a taxa de decisões por segundo de um tipo de limite
+type decisionRate struct {
+	Allowed float64 `json:"allowed_per_second"`
+	Denied  float64 `json:"denied_per_second"`
+}
+
+// dashboardSnapshot é o estado do rate limiter enviado ao dashboard
+type dashboardSnapshot struct {
+	Time   time.Time               `json:"time"`
+	Rates  map[string]decisionRate `json:"rates"`
+	Limits map[string]int64        `json:"limits"`
+	Blocks []blockEntry            `json:"blocks"`
+	// BlocksTruncated indica que há mais bloqueios que os enviados
+	BlocksTruncated bool `json:"blocks_truncated"`
+	// Top contém os maiores consumidores por tipo de limite (ausente sem tracker)
+	Top map[string][]consumerEntry `json:"top,omitempty"`
+}
+
+// decisionSample guarda os totais de decisões da última amostra, para o
+// cálculo das taxas
+type decisionSample struct {
+	counts map[string]limiter.DecisionCount
+	time   time.Time
+}
+
+// streamEvents envia snapshots do rate limiter como server-sent events
+// ("snapshot" ou "error"), um imediatamente e os demais a cada interval
+// segundos (padrão: 2), até o cliente desconectar ou o servidor encerrar.
+// As taxas de decisão são desta instância.
+func (h *Handler) streamEvents(c *gin.Context) {
+	interval := defaultEventsInterval
+	if value := c.Query("interval"); value != "" {
+		seconds, err := strconv.Atoi(value)
+		if err != nil || seconds < 1 || seconds > 60 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "invalid interval",
+			})
+			return
+		}
+		interval = time.Duration(seconds) * time.Second
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	ctx := c.Request.Context()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	sample := decisionSample{counts: h.rateLimiter.Decisions(), time: time.Now()}
+	first := true
+	c.Stream(func(w io.Writer) bool {
+		if !first {
+			select {
+			case <-ctx.Done():
+				return false
+			case <-h.done:
+				return false
+			case <-ticker.C:
+			}
+		}
+		first = false
+
+		snapshot, err := h.snapshot(ctx, &sample)
+		if err != nil {
+			c.SSEvent("error", gin.H{"error": err.Error()})
+			return true
+		}
+		c.SSEvent("snapshot", snapshot)
+		return true
+	})
+}
+
+// snapshot monta o estado atual e atualiza a amostra de decisões
+func (h *Handler) snapshot(ctx context.Context, sample *decisionSample) (*dashboardSnapshot, error) {
+	now := time.Now()
+	counts := h.rateLimiter.Decisions()
+	elapsed := now.Sub(sample.time).Seconds()
+
+	snapshot := &dashboardSnapshot{
+		Time:   now,
+		Rates:  make(map[string]decisionRate, len(counts)),
+		Limits: make(map[string]int64, len(counts)),
+		Blocks: make([]blockEntry, 0),
+	}
+	for limitType, count := range counts {
+		rate := decisionRate{}
+		if elapsed > 0 {
+			previous := sample.counts[limitType]
+			rate.Allowed = float64(count.Allowed-previous.Allowed) / elapsed
+			rate.Denied = float64(count.Denied-previous.Denied) / elapsed
+		}
+		snapshot.Rates[limitType] = rate
+		snapshot.Limits[limitType], _ = h.rateLimiter.Limit(limitType, "")
+	}
+	sample.counts = counts
+	sample.time = now
+
+	// Percorre os bloqueios até o limite do snapshot
+	var cursor uint64
+	for {
+		blocks, next, err := h.rateLimiter.ScanBlocks(ctx, cursor, 1000)
+		if err != nil {
+			return nil, err
+		}
+		for _, block := range blocks {
+			snapshot.Blocks = append(snapshot.Blocks, blockEntry{
+				LimitType:     block.LimitType,
+				KeyIdentifier: block.KeyIdentifier,
+				TTLSeconds:    seconds(block.TTL),
+			})
+		}
+		cursor = next
+		if cursor == 0 || len(snapshot.Blocks) >= maxSnapshotBlocks {
+			break
+		}
+	}
+	if cursor != 0 || len(snapshot.Blocks) > maxSnapshotBlocks {
+		snapshot.BlocksTruncated = true
+		snapshot.Blocks = snapshot.Blocks[:min(len(snapshot.Blocks), maxSnapshotBlocks)]
+	}
+
+	if tracker := h.rateLimiter.HeavyHitters(); tracker != nil {
+		snapshot.Top = make(map[string][]consumerEntry, len(counts))
+		for limitType := range counts {
+			consumers, err := tracker.Top(ctx, limitType, snapshotTopWindow, snapshotTopSize)
+			if err != nil {
+				return nil, err
+			}
+			entries := make([]consumerEntry, 0, len(consumers))
+			for _, consumer := range consumers {
+				entries = append(entries, consumerEntry{
+					KeyIdentifier: consumer.Identifier,
+					Requests:      consumer.Requests,
+					Denied:        consumer.Denied,
+					PeakRate:      consumer.PeakRate,
+				})
+			}
+			snapshot.Top[limitType] = entries
+		}
+	}
+	return snapshot, nil
+}
@@ -0,0 +1,109 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/m4rcelotoledo/rate-limiter/pkg/access"
+	"github.com/m4rcelotoledo/rate-limiter/pkg/limiter"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Resultados de explain: o limite é aplicado (limited), a requisição é
+// liberada (allowlisted) ou rejeitada (denylisted, unknown_token) antes dele
+const (
+	explainLimited      = "limited"
+	explainAllowlisted  = "allowlisted"
+	explainDenylisted   = "denylisted"
+	explainUnknownToken = "unknown_token"
+)
+
+// explainRequest descreve uma requisição de um cliente
+type explainRequest struct {
+	// IP é o endereço remoto da requisição; headers como X-Forwarded-For têm
+	// prioridade se ele for um proxy confiável, como no middleware
+	IP      string            `json:"ip"`
+	Headers map[string]string `json:"headers"`
+	// Query é a query string da requisição (ex: "api_key=abc123")
+	Query string `json:"query"`
+}
+
+type explainResponse struct {
+	Result       string `json:"result"`
+	ClientIP     string `json:"client_ip"`
+	TokenPresent bool   `json:"token_present"`
+	Access       string `json:"access"`
+	Tier         string `json:"tier,omitempty"`
+	// Limite aplicado e estado atual do cliente (apenas com result "limited")
+	*statusResponse
+}
+
+// explainLimits mostra como o rate limiter trataria a requisição descrita:
+// listas de acesso, validação do token, limite efetivo e estado atual do
+// cliente, sem contabilizar a requisição
+func (h *Handler) explainLimits(c *gin.Context) {
+	var req explainRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid request body",
+		})
+		return
+	}
+
+	query, err := url.ParseQuery(req.Query)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid query",
+		})
+		return
+	}
+
+	ctx := c.Request.Context()
+	r := (&http.Request{
+		Method:     http.MethodGet,
+		URL:        &url.URL{Path: "/", RawQuery: query.Encode()},
+		Header:     make(http.Header),
+		RemoteAddr: req.IP,
+	}).WithContext(ctx)
+	for name, value := range req.Headers {
+		r.Header.Set(name, value)
+	}
+
+	identity, err := h.rateLimiter.Identify(ctx, r)
+	if identity.ClientIP == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "missing client ip",
+		})
+		return
+	}
+
+	response := explainResponse{
+		ClientIP:     identity.ClientIP,
+		TokenPresent: identity.Token != "",
+		Access:       identity.Access.String(),
+		Tier:         identity.Tier,
+	}
+	switch {
+	case identity.Access == access.Allow:
+		response.Result = explainAllowlisted
+	case identity.Access == access.Deny:
+		response.Result = explainDenylisted
+	case errors.Is(err, limiter.ErrUnknownToken):
+		response.Result = explainUnknownToken
+	case err != nil:
+		storageError(c, err)
+		return
+	default:
+		status, err := h.rateLimiter.Inspect(ctx, identity.Identifier, identity.LimitType, identity.Tier)
+		if err != nil {
+			storageError(c, err)
+			return
+		}
+		statusResponse := newStatusResponse(status)
+		response.Result = explainLimited
+		response.statusResponse = &statusResponse
+	}
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,278 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/m4rcelotoledo/rate-limiter/pkg/audit"
+	"github.com/m4rcelotoledo/rate-limiter/pkg/limiter"
+	"github.com/m4rcelotoledo/rate-limiter/pkg/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+type statusRequest struct {
+	identifierRequest
+	// Tier seleciona os limites do plano do cliente ao calcular a cota restante
+	Tier string `json:"tier"`
+}
+
+type blockRequest struct {
+	identifierRequest
+	DurationSeconds int    `json:"duration_seconds" binding:"required,gt=0"`
+	Reason          string `json:"reason"`
+}
+
+type statusResponse struct {
+	LimitType            string `json:"limit_type"`
+	KeyIdentifier        string `json:"key_identifier"`
+	Rule                 string `json:"rule"`
+	Count                int64  `json:"count"`
+	Limit                int64  `json:"limit"`
+	Remaining            int64  `json:"remaining"`
+	BlockDurationSeconds int64  `json:"block_duration_seconds"`
+	Blocked              bool   `json:"blocked"`
+	BlockTTLSeconds      int64  `json:"block_ttl_seconds"`
+}
+
+// limitStatus retorna o contador, a cota restante e o bloqueio de um cliente
+func (h *Handler) limitStatus(c *gin.Context) {
+	var req statusRequest
+	if !bindIdentifier(c, &req, &req.identifierRequest) {
+		return
+	}
+	h.respondStatus(c, &req.identifierRequest, req.Tier)
+}
+
+// blockLimit bloqueia manualmente um cliente pela duração informada
+func (h *Handler) blockLimit(c *gin.Context) {
+	var req blockRequest
+	if !bindIdentifier(c, &req, &req.identifierRequest) {
+		return
+	}
+
+	ctx := c.Request.Context()
+	duration := time.Duration(req.DurationSeconds) * time.Second
+	if err := req.block(ctx, h.rateLimiter, duration); err != nil {
+		storageError(c, err)
+		return
+	}
+
+	reason := req.Reason
+	if reason == "" {
+		reason = "manual block"
+	}
+	expiresAt := time.Now().Add(duration)
+	h.rateLimiter.AuditEmitter().Emit(ctx, audit.Event{
+		Type:       audit.BlockEvent,
+		Source:     audit.SourceAdmin,
+		LimitType:  req.LimitType,
+		Identifier: req.redacted(h.rateLimiter),
+		Duration:   duration,
+		ExpiresAt:  &expiresAt,
+		Reason:     reason,
+	})
+
+	h.respondStatus(c, &req.identifierRequest, "")
+}
+
+// unblockLimit remove o bloqueio de um cliente
+func (h *Handler) unblockLimit(c *gin.Context) {
+	var req identifierRequest
+	if !bindIdentifier(c, &req, &req) {
+		return
+	}
+
+	ctx := c.Request.Context()
+	if err := req.unblock(ctx, h.rateLimiter); err != nil {
+		storageError(c, err)
+		return
+	}
+
+	h.rateLimiter.AuditEmitter().Emit(ctx, audit.Event{
+		Type:       audit.UnblockEvent,
+		Source:     audit.SourceAdmin,
+		LimitType:  req.LimitType,
+		Identifier: req.redacted(h.rateLimiter),
+		Reason:     "manual unblock",
+	})
+
+	h.respondStatus(c, &req, "")
+}
+
+// resetLimit zera o contador de requisições de um cliente
+func (h *Handler) resetLimit(c *gin.Context) {
+	var req identifierRequest
+	if !bindIdentifier(c, &req, &req) {
+		return
+	}
+
+	if err := req.resetCounter(c.Request.Context(), h.rateLimiter); err != nil {
+		storageError(c, err)
+		return
+	}
+
+	h.respondStatus(c, &req, "")
+}
+
+type blockEntry struct {
+	LimitType     string `json:"limit_type"`
+	KeyIdentifier string `json:"key_identifier"`
+	TTLSeconds    int64  `json:"ttl_seconds"`
+}
+
+type blocksResponse struct {
+	Blocks     []blockEntry `json:"blocks"`
+	NextCursor uint64       `json:"next_cursor"`
+}
+
+// listBlocks retorna uma página dos bloqueios ativos. Comece sem cursor e
+// repita a chamada com next_cursor até ele ser 0.
+func (h *Handler) listBlocks(c *gin.Context) {
+	cursor, err := strconv.ParseUint(c.DefaultQuery("cursor", "0"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid cursor",
+		})
+		return
+	}
+	count, err := strconv.ParseInt(c.DefaultQuery("count", "100"), 10, 64)
+	if err != nil || count <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid count",
+		})
+		return
+	}
+
+	blocks, next, err := h.rateLimiter.ScanBlocks(c.Request.Context(), cursor, count)
+	if errors.Is(err, storage.ErrInvalidCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid cursor",
+		})
+		return
+	}
+	if err != nil {
+		storageError(c, err)
+		return
+	}
+
+	response := blocksResponse{
+		Blocks:     make([]blockEntry, 0, len(blocks)),
+		NextCursor: next,
+	}
+	for _, block := range blocks {
+		response.Blocks = append(response.Blocks, blockEntry{
+			LimitType:     block.LimitType,
+			KeyIdentifier: block.KeyIdentifier,
+			TTLSeconds:    seconds(block.TTL),
+		})
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+type consumerEntry struct {
+	KeyIdentifier string `json:"key_identifier"`
+	Requests      int64  `json:"requests"`
+	Denied        int64  `json:"denied"`
+	PeakRate      int64  `json:"peak_rate"`
+}
+
+type topResponse struct {
+	LimitType     string          `json:"limit_type"`
+	WindowMinutes int             `json:"window_minutes"`
+	Limit         int64           `json:"limit"`
+	Consumers     []consumerEntry `json:"consumers"`
+}
+
+// topConsumers retorna os identificadores com mais requisições nos últimos
+// minutes minutos (padrão: 5), com as negações e o pico por segundo de cada um
+func (h *Handler) topConsumers(c *gin.Context) {
+	tracker := h.rateLimiter.HeavyHitters()
+	if tracker == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "heavy hitters tracking is not enabled",
+		})
+		return
+	}
+
+	limitType := c.DefaultQuery("limit_type", "token")
+	limit, err := h.rateLimiter.Limit(limitType, "")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	minutes, err := strconv.Atoi(c.DefaultQuery("minutes", "5"))
+	if err != nil || minutes <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid minutes",
+		})
+		return
+	}
+	n, err := strconv.Atoi(c.DefaultQuery("n", "10"))
+	if err != nil || n <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid n",
+		})
+		return
+	}
+
+	consumers, err := tracker.Top(c.Request.Context(), limitType, time.Duration(minutes)*time.Minute, n)
+	if err != nil {
+		storageError(c, err)
+		return
+	}
+
+	response := topResponse{
+		LimitType:     limitType,
+		WindowMinutes: minutes,
+		Limit:         limit,
+		Consumers:     make([]consumerEntry, 0, len(consumers)),
+	}
+	for _, consumer := range consumers {
+		response.Consumers = append(response.Consumers, consumerEntry{
+			KeyIdentifier: consumer.Identifier,
+			Requests:      consumer.Requests,
+			Denied:        consumer.Denied,
+			PeakRate:      consumer.PeakRate,
+		})
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *Handler) respondStatus(c *gin.Context, req *identifierRequest, tier string) {
+	status, err := req.inspect(c.Request.Context(), h.rateLimiter, tier)
+	if err != nil {
+		storageError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, newStatusResponse(status))
+}
+
+func newStatusResponse(status *limiter.Status) statusResponse {
+	return statusResponse{
+		LimitType:            status.LimitType,
+		KeyIdentifier:        status.KeyIdentifier,
+		Rule:                 status.Rule,
+		Count:                status.Count,
+		Limit:                status.Limit,
+		Remaining:            status.Remaining,
+		BlockDurationSeconds: seconds(status.BlockDuration),
+		Blocked:              status.Blocked,
+		BlockTTLSeconds:      seconds(status.BlockTTL),
+	}
+}
+
+// seconds arredonda a duração para segundos inteiros
+func seconds(d time.Duration) int64 {
+	return int64(d.Round(time.Second) / time.Second)
+}
+
+func storageError(c *gin.Context, err error) {
+	c.JSON(http.StatusInternalServerError, gin.H{
+		"error": err.Error(),
+	})
+}
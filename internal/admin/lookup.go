@@ -0,0 +1,120 @@
+package admin
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/m4rcelotoledo/rate-limiter/pkg/limiter"
+
+	"github.com/gin-gonic/gin"
+)
+
+// identifierRequest identifica um cliente (token ou IP) nas rotas de
+// administração, pelo identificador original ou pelo identificador das chaves
+// (key_identifier, ex: o hash de um token listado em /admin/limits/blocks). O
+// identificador é enviado no corpo para não aparecer em logs de URL.
+type identifierRequest struct {
+	Identifier    string `json:"identifier"`
+	KeyIdentifier string `json:"key_identifier"`
+	LimitType     string `json:"limit_type"`
+}
+
+type lookupResponse struct {
+	LimitType     string `json:"limit_type"`
+	KeyIdentifier string `json:"key_identifier"`
+	RateLimitKey  string `json:"rate_limit_key"`
+	BlockKey      string `json:"block_key"`
+}
+
+// lookup mapeia um token (ou IP) conhecido para as chaves usadas no storage,
+// permitindo localizar um cliente quando os tokens são armazenados com hash.
+func (h *Handler) lookup(c *gin.Context) {
+	var req identifierRequest
+	if !bindIdentifier(c, &req, &req) {
+		return
+	}
+	if req.Identifier == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "lookup requires identifier",
+		})
+		return
+	}
+
+	rateLimitKey, blockKey := h.rateLimiter.StorageKeys(req.Identifier, req.LimitType)
+	c.JSON(http.StatusOK, lookupResponse{
+		LimitType:     req.LimitType,
+		KeyIdentifier: h.rateLimiter.KeyIdentifier(req.Identifier, req.LimitType),
+		RateLimitKey:  rateLimitKey,
+		BlockKey:      blockKey,
+	})
+}
+
+// bindIdentifier lê o corpo da requisição em req e valida id, o
+// identifierRequest contido em req: exatamente um identificador e o tipo de
+// limite (padrão: "token"). Responde com 400 e retorna false se o corpo for
+// inválido.
+func bindIdentifier(c *gin.Context, req any, id *identifierRequest) bool {
+	if err := c.ShouldBindJSON(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid request body",
+		})
+		return false
+	}
+
+	if (id.Identifier == "") == (id.KeyIdentifier == "") {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "exactly one of identifier or key_identifier is required",
+		})
+		return false
+	}
+	if id.LimitType == "" {
+		id.LimitType = "token"
+	}
+	if id.LimitType != "ip" && id.LimitType != "token" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid limit type: " + id.LimitType,
+		})
+		return false
+	}
+	return true
+}
+
+// As operações abaixo usam o identificador original ou o das chaves,
+// conforme informado na requisição
+
+func (r *identifierRequest) inspect(ctx context.Context, rl *limiter.RateLimiter, tier string) (*limiter.Status, error) {
+	if r.KeyIdentifier != "" {
+		return rl.InspectKey(ctx, r.KeyIdentifier, r.LimitType, tier)
+	}
+	return rl.Inspect(ctx, r.Identifier, r.LimitType, tier)
+}
+
+func (r *identifierRequest) block(ctx context.Context, rl *limiter.RateLimiter, duration time.Duration) error {
+	if r.KeyIdentifier != "" {
+		return rl.BlockKey(ctx, r.KeyIdentifier, r.LimitType, duration)
+	}
+	return rl.Block(ctx, r.Identifier, r.LimitType, duration)
+}
+
+func (r *identifierRequest) unblock(ctx context.Context, rl *limiter.RateLimiter) error {
+	if r.KeyIdentifier != "" {
+		return rl.UnblockKey(ctx, r.KeyIdentifier, r.LimitType)
+	}
+	return rl.Unblock(ctx, r.Identifier, r.LimitType)
+}
+
+func (r *identifierRequest) resetCounter(ctx context.Context, rl *limiter.RateLimiter) error {
+	if r.KeyIdentifier != "" {
+		return rl.ResetCounterKey(ctx, r.KeyIdentifier, r.LimitType)
+	}
+	return rl.ResetCounter(ctx, r.Identifier, r.LimitType)
+}
+
+// redacted retorna o identificador usado na auditoria, sem tokens em texto puro
+func (r *identifierRequest) redacted(rl *limiter.RateLimiter) string {
+	if r.KeyIdentifier != "" {
+		return rl.RedactedKeyIdentifier(r.KeyIdentifier, r.LimitType)
+	}
+	return rl.RedactedIdentifier(r.Identifier, r.LimitType)
+}
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/m4rcelotoledo/rate-limiter/pkg/jwtauth"
+	"github.com/m4rcelotoledo/rate-limiter/pkg/limiter"
+	"github.com/m4rcelotoledo/rate-limiter/pkg/middleware"
+)
+
+// LimiterConfig converte a configuração nos limites do rate limiter
+func (c *Config) LimiterConfig() *limiter.Config {
+	limiterConfig := &limiter.Config{
+		IPRequestsPerSecond:       c.RateLimitIPRequestsPerSecond,
+		IPBlockDurationSeconds:    c.RateLimitIPBlockDurationSeconds,
+		TokenRequestsPerSecond:    c.RateLimitTokenRequestsPerSecond,
+		TokenBlockDurationSeconds: c.RateLimitTokenBlockDurationSeconds,
+		IPv4PrefixLength:          c.RateLimitIPv4PrefixLength,
+		IPv6PrefixLength:          c.RateLimitIPv6PrefixLength,
+		TokenHeaders:              c.RateLimitTokenHeaders,
+		TokenQueryParam:           c.RateLimitTokenQueryParam,
+		RejectUnknownTokens:       c.RejectUnknownTokens,
+		Tiers:                     make(map[string]limiter.Tier, len(c.RateLimitTiers)),
+		TokenHashSecret:           c.RateLimitTokenHashSecret,
+		KeyNamespace:              c.RateLimitKeyNamespace,
+		LegacyKeyFallback:         c.RateLimitLegacyKeyFallback,
+		ShadowRules:               make(map[string]limiter.Tier, len(c.RateLimitShadowRules)),
+		TrustedProxies:            c.RateLimitTrustedProxies,
+	}
+	for name, tier := range c.RateLimitTiers {
+		limiterConfig.Tiers[name] = limiter.Tier{
+			RequestsPerSecond:    tier.RequestsPerSecond,
+			BlockDurationSeconds: tier.BlockDurationSeconds,
+		}
+	}
+	for rule, candidate := range c.RateLimitShadowRules {
+		limiterConfig.ShadowRules[rule] = limiter.Tier{
+			RequestsPerSecond:    candidate.RequestsPerSecond,
+			BlockDurationSeconds: candidate.BlockDurationSeconds,
+		}
+	}
+	return limiterConfig
+}
+
+// RejectionHandler cria a resposta de rejeição a partir da configuração,
+// carregando as mensagens traduzidas e o template, se informados
+func (c *Config) RejectionHandler() (middleware.RejectionHandler, error) {
+	rejectionConfig := middleware.RejectionConfig{
+		Format:          middleware.RejectionFormat(c.RejectionFormat),
+		StatusCode:      c.RejectionStatusCode,
+		RuleStatusCodes: c.RejectionRuleStatusCodes,
+		DefaultLanguage: c.RejectionDefaultLanguage,
+	}
+
+	if c.RejectionMessagesFile != "" {
+		data, err := os.ReadFile(c.RejectionMessagesFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read rejection messages: %w", err)
+		}
+		if err := json.Unmarshal(data, &rejectionConfig.Messages); err != nil {
+			return nil, fmt.Errorf("failed to parse rejection messages: %w", err)
+		}
+	}
+
+	if c.RejectionTemplateFile != "" {
+		data, err := os.ReadFile(c.RejectionTemplateFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read rejection template: %w", err)
+		}
+		rejectionConfig.Template = string(data)
+	}
+
+	return middleware.NewRejectionHandler(rejectionConfig)
+}
+
+// JWTVerifier cria o verificador de JWT a partir do segredo HMAC e/ou do
+// arquivo JWKS. Retorna nil quando nenhum dos dois está configurado.
+func (c *Config) JWTVerifier() (*jwtauth.Verifier, error) {
+	if c.JWTHMACSecret == "" && c.JWTJWKSFile == "" {
+		return nil, nil
+	}
+
+	jwtConfig := jwtauth.Config{
+		HMACSecret:    []byte(c.JWTHMACSecret),
+		IdentityClaim: c.JWTIdentityClaim,
+		PlanClaim:     c.JWTPlanClaim,
+		Issuer:        c.JWTIssuer,
+		Audience:      c.JWTAudience,
+		Leeway:        time.Duration(c.JWTLeewaySeconds) * time.Second,
+	}
+	if c.JWTJWKSFile != "" {
+		publicKeys, err := jwtauth.LoadJWKSFile(c.JWTJWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		jwtConfig.PublicKeys = publicKeys
+	}
+
+	return jwtauth.NewVerifier(jwtConfig)
+}
@@ -1,17 +1,76 @@
 package config
 
 import (
+	"fmt"
+	"net/netip"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
 
+// Tier define os limites de um plano de clientes
+type Tier struct {
+	RequestsPerSecond    int
+	BlockDurationSeconds int
+}
+
 type Config struct {
 	RateLimitIPRequestsPerSecond       int
 	RateLimitIPBlockDurationSeconds    int
 	RateLimitTokenRequestsPerSecond    int
 	RateLimitTokenBlockDurationSeconds int
+	RateLimitIPv4PrefixLength          int
+	RateLimitIPv6PrefixLength          int
+	RateLimitTiers                     map[string]Tier
+	RateLimitTokenHashSecret           string
+	RateLimitKeyNamespace              string
+	RateLimitLegacyKeyFallback         bool
+	RateLimitShadowRules               map[string]Tier
+	RateLimitTrustedProxies            []netip.Prefix
+	RateLimitTokenHeaders              []string
+	RateLimitTokenQueryParam           string
+	RateLimitHeaders                   string
+	RejectionFormat                    string
+	RejectionStatusCode                int
+	RejectionRuleStatusCodes           map[string]int
+	RejectionMessagesFile              string
+	RejectionDefaultLanguage           string
+	RejectionTemplateFile              string
+	KeyStoreType                       string
+	KeyStoreFile                       string
+	KeyStoreRedisSet                   string
+	KeyStoreHMACSecret                 string
+	RejectUnknownTokens                bool
+	JWTHMACSecret                      string
+	JWTJWKSFile                        string
+	JWTIdentityClaim                   string
+	JWTPlanClaim                       string
+	JWTIssuer                          string
+	JWTAudience                        string
+	JWTLeewaySeconds                   int
+	AccessListFile                     string
+	AdminAPIToken                      string
+	MetricsEnabled                     bool
+	MetricsPath                        string
+	MetricsBlockCountIntervalSeconds   int
+	TracingExporter                    string
+	TracingServiceName                 string
+	TracingSampleRatio                 float64
+	LogLevel                           string
+	LogFormat                          string
+	LogAllowedSampleRate               float64
+	LogPlainIPs                        bool
+	AuditSink                          string
+	AuditFile                          string
+	AuditRedisStream                   string
+	AuditRedisStreamMaxLen             int
+	AuditWebhookURL                    string
+	AuditQueueSize                     int
+	HeavyHittersEnabled                bool
+	HeavyHittersRetentionMinutes       int
+	HeavyHittersCapacity               int
 	RedisHost                          string
 	RedisPort                          string
 	RedisPassword                      string
@@ -23,11 +82,73 @@ func Load() (*Config, error) {
 	// Carrega o arquivo .env se existir
 	godotenv.Load()
 
+	return fromEnv()
+}
+
+// LoadFile é como Load, mas carrega o arquivo informado no lugar do .env e
+// retorna erro se ele não existir ou não puder ser lido. Variáveis já
+// definidas no ambiente têm prioridade sobre o arquivo.
+func LoadFile(path string) (*Config, error) {
+	if err := godotenv.Load(path); err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", path, err)
+	}
+
+	return fromEnv()
+}
+
+// fromEnv lê a configuração das variáveis de ambiente
+func fromEnv() (*Config, error) {
 	config := &Config{
 		RateLimitIPRequestsPerSecond:       getEnvAsInt("RATE_LIMIT_IP_REQUESTS_PER_SECOND", 10),
 		RateLimitIPBlockDurationSeconds:    getEnvAsInt("RATE_LIMIT_IP_BLOCK_DURATION_SECONDS", 300),
 		RateLimitTokenRequestsPerSecond:    getEnvAsInt("RATE_LIMIT_TOKEN_REQUESTS_PER_SECOND", 100),
 		RateLimitTokenBlockDurationSeconds: getEnvAsInt("RATE_LIMIT_TOKEN_BLOCK_DURATION_SECONDS", 600),
+		RateLimitIPv4PrefixLength:          getEnvAsInt("RATE_LIMIT_IPV4_PREFIX_LENGTH", 32),
+		RateLimitIPv6PrefixLength:          getEnvAsInt("RATE_LIMIT_IPV6_PREFIX_LENGTH", 64),
+		RateLimitTokenHashSecret:           getEnv("RATE_LIMIT_TOKEN_HASH_SECRET", ""),
+		RateLimitKeyNamespace:              getEnv("RATE_LIMIT_KEY_NAMESPACE", ""),
+		RateLimitLegacyKeyFallback:         getEnvAsBool("RATE_LIMIT_LEGACY_KEY_FALLBACK", false),
+		RateLimitTokenHeaders:              getEnvAsList("RATE_LIMIT_TOKEN_HEADERS", nil),
+		RateLimitTokenQueryParam:           getEnv("RATE_LIMIT_TOKEN_QUERY_PARAM", ""),
+		RateLimitHeaders:                   getEnv("RATE_LIMIT_HEADERS", "legacy"),
+		RejectionFormat:                    getEnv("RATE_LIMIT_REJECTION_FORMAT", "json"),
+		RejectionStatusCode:                getEnvAsInt("RATE_LIMIT_REJECTION_STATUS", 429),
+		RejectionMessagesFile:              getEnv("RATE_LIMIT_REJECTION_MESSAGES_FILE", ""),
+		RejectionDefaultLanguage:           getEnv("RATE_LIMIT_REJECTION_DEFAULT_LANGUAGE", "en"),
+		RejectionTemplateFile:              getEnv("RATE_LIMIT_REJECTION_TEMPLATE_FILE", ""),
+		KeyStoreType:                       getEnv("KEY_STORE_TYPE", ""),
+		KeyStoreFile:                       getEnv("KEY_STORE_FILE", ""),
+		KeyStoreRedisSet:                   getEnv("KEY_STORE_REDIS_SET", "api_keys"),
+		KeyStoreHMACSecret:                 getEnv("KEY_STORE_HMAC_SECRET", ""),
+		RejectUnknownTokens:                getEnvAsBool("REJECT_UNKNOWN_TOKENS", false),
+		JWTHMACSecret:                      getEnv("JWT_HMAC_SECRET", ""),
+		JWTJWKSFile:                        getEnv("JWT_JWKS_FILE", ""),
+		JWTIdentityClaim:                   getEnv("JWT_IDENTITY_CLAIM", "sub"),
+		JWTPlanClaim:                       getEnv("JWT_PLAN_CLAIM", ""),
+		JWTIssuer:                          getEnv("JWT_ISSUER", ""),
+		JWTAudience:                        getEnv("JWT_AUDIENCE", ""),
+		JWTLeewaySeconds:                   getEnvAsInt("JWT_LEEWAY_SECONDS", 30),
+		AccessListFile:                     getEnv("ACCESS_LIST_FILE", ""),
+		AdminAPIToken:                      getEnv("ADMIN_API_TOKEN", ""),
+		MetricsEnabled:                     getEnvAsBool("METRICS_ENABLED", true),
+		MetricsPath:                        getEnv("METRICS_PATH", "/metrics"),
+		MetricsBlockCountIntervalSeconds:   getEnvAsInt("METRICS_BLOCK_COUNT_INTERVAL_SECONDS", 30),
+		TracingExporter:                    getEnv("TRACING_EXPORTER", ""),
+		TracingServiceName:                 getEnv("TRACING_SERVICE_NAME", "rate-limiter"),
+		TracingSampleRatio:                 getEnvAsFloat("TRACING_SAMPLE_RATIO", 1.0),
+		LogLevel:                           getEnv("LOG_LEVEL", "info"),
+		LogFormat:                          getEnv("LOG_FORMAT", "json"),
+		LogAllowedSampleRate:               getEnvAsFloat("LOG_ALLOWED_SAMPLE_RATE", 0),
+		LogPlainIPs:                        getEnvAsBool("LOG_PLAIN_IPS", false),
+		AuditSink:                          getEnv("AUDIT_SINK", ""),
+		AuditFile:                          getEnv("AUDIT_FILE", "audit.log"),
+		AuditRedisStream:                   getEnv("AUDIT_REDIS_STREAM", "rate_limiter:audit"),
+		AuditRedisStreamMaxLen:             getEnvAsInt("AUDIT_REDIS_STREAM_MAXLEN", 100000),
+		AuditWebhookURL:                    getEnv("AUDIT_WEBHOOK_URL", ""),
+		AuditQueueSize:                     getEnvAsInt("AUDIT_QUEUE_SIZE", 1000),
+		HeavyHittersEnabled:                getEnvAsBool("HEAVY_HITTERS_ENABLED", true),
+		HeavyHittersRetentionMinutes:       getEnvAsInt("HEAVY_HITTERS_RETENTION_MINUTES", 60),
+		HeavyHittersCapacity:               getEnvAsInt("HEAVY_HITTERS_CAPACITY", 1000),
 		RedisHost:                          getEnv("REDIS_HOST", "localhost"),
 		RedisPort:                          getEnv("REDIS_PORT", "6379"),
 		RedisPassword:                      getEnv("REDIS_PASSWORD", ""),
@@ -35,9 +156,149 @@ func Load() (*Config, error) {
 		ServerPort:                         getEnv("SERVER_PORT", "8080"),
 	}
 
+	tiers, err := parseTiers(getEnv("RATE_LIMIT_TIERS", ""))
+	if err != nil {
+		return nil, err
+	}
+	config.RateLimitTiers = tiers
+
+	shadowRules, err := parseShadowRules(getEnv("RATE_LIMIT_SHADOW_RULES", ""))
+	if err != nil {
+		return nil, err
+	}
+	config.RateLimitShadowRules = shadowRules
+
+	ruleStatusCodes, err := parseRuleStatusCodes(getEnv("RATE_LIMIT_REJECTION_RULE_STATUS", ""))
+	if err != nil {
+		return nil, err
+	}
+	config.RejectionRuleStatusCodes = ruleStatusCodes
+
+	trustedProxies, err := parseTrustedProxies(getEnvAsList("RATE_LIMIT_TRUSTED_PROXIES", nil))
+	if err != nil {
+		return nil, err
+	}
+	config.RateLimitTrustedProxies = trustedProxies
+
+	// Authorization costuma trazer credenciais de outros serviços (ex: Basic
+	// ou sessões); sem validação, cada valor ganharia um limite por token
+	// próprio. Por padrão, o header só é lido se os tokens forem validados.
+	if config.RateLimitTokenHeaders == nil {
+		config.RateLimitTokenHeaders = []string{"API_KEY"}
+		if config.validatesTokens() {
+			config.RateLimitTokenHeaders = append(config.RateLimitTokenHeaders, "Authorization")
+		}
+	}
+
 	return config, nil
 }
 
+// validatesTokens indica se os tokens são validados por um key store ou por
+// um verificador de JWT
+func (c *Config) validatesTokens() bool {
+	return c.KeyStoreType != "" || c.JWTHMACSecret != "" || c.JWTJWKSFile != ""
+}
+
+// parseTiers lê tiers no formato nome:requisições_por_segundo:bloqueio_em_segundos,
+// separados por vírgula (ex: free:10:300,gold:1000:60)
+func parseTiers(value string) (map[string]Tier, error) {
+	tiers := make(map[string]Tier)
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		parts := strings.Split(item, ":")
+		if len(parts) != 3 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid rate limit tier %q: expected name:requests:block_seconds", item)
+		}
+		requests, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid requests per second for tier %q: %w", parts[0], err)
+		}
+		block, err := strconv.Atoi(parts[2])
+		if err != nil {
+			return nil, fmt.Errorf("invalid block duration for tier %q: %w", parts[0], err)
+		}
+
+		tiers[parts[0]] = Tier{RequestsPerSecond: requests, BlockDurationSeconds: block}
+	}
+	return tiers, nil
+}
+
+// parseShadowRules lê os limites candidatos em shadow mode no formato
+// regra=requisições_por_segundo:bloqueio_em_segundos, separados por vírgula
+// (ex: ip=5:60,token:free=20:300)
+func parseShadowRules(value string) (map[string]Tier, error) {
+	rules := make(map[string]Tier)
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		rule, limits, found := strings.Cut(item, "=")
+		requests, block, hasBlock := strings.Cut(limits, ":")
+		if !found || rule == "" || !hasBlock {
+			return nil, fmt.Errorf("invalid shadow rule %q: expected rule=requests:block_seconds", item)
+		}
+		requestsPerSecond, err := strconv.Atoi(requests)
+		if err != nil {
+			return nil, fmt.Errorf("invalid requests per second for shadow rule %q: %w", rule, err)
+		}
+		blockSeconds, err := strconv.Atoi(block)
+		if err != nil {
+			return nil, fmt.Errorf("invalid block duration for shadow rule %q: %w", rule, err)
+		}
+
+		rules[rule] = Tier{RequestsPerSecond: requestsPerSecond, BlockDurationSeconds: blockSeconds}
+	}
+	return rules, nil
+}
+
+// parseTrustedProxies lê as redes dos proxies confiáveis, em notação CIDR ou
+// como IPs individuais (ex: 10.0.0.0/8,192.168.1.10)
+func parseTrustedProxies(values []string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, value := range values {
+		if addr, err := netip.ParseAddr(value); err == nil {
+			prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: expected an IP or CIDR", value)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+// parseRuleStatusCodes lê status de rejeição por regra no formato regra=status,
+// separados por vírgula (ex: token:free=503,ip=429)
+func parseRuleStatusCodes(value string) (map[string]int, error) {
+	statusCodes := make(map[string]int)
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		rule, status, found := strings.Cut(item, "=")
+		if !found || rule == "" {
+			return nil, fmt.Errorf("invalid rejection rule status %q: expected rule=status", item)
+		}
+		statusCode, err := strconv.Atoi(status)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rejection status for rule %q: %w", rule, err)
+		}
+
+		statusCodes[rule] = statusCode
+	}
+	return statusCodes, nil
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -53,3 +314,39 @@ func getEnvAsInt(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
+
+func getEnvAsBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
+func getEnvAsList(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	var values []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			values = append(values, item)
+		}
+	}
+	if len(values) == 0 {
+		return defaultValue
+	}
+	return values
+}
@@ -0,0 +1,179 @@
+package config
+
+import (
+	"net/netip"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLoad_TokenHeaders(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		expected []string
+	}{
+		{name: "Without token validation", expected: []string{"API_KEY"}},
+		{name: "With key store", env: map[string]string{"KEY_STORE_TYPE": "redis"}, expected: []string{"API_KEY", "Authorization"}},
+		{name: "With JWT verifier", env: map[string]string{"JWT_HMAC_SECRET": "jwt-secret"}, expected: []string{"API_KEY", "Authorization"}},
+		{name: "Explicit headers", env: map[string]string{"RATE_LIMIT_TOKEN_HEADERS": "X-API-Key", "KEY_STORE_TYPE": "redis"}, expected: []string{"X-API-Key"}},
+		{name: "Spaces and empty items", env: map[string]string{"RATE_LIMIT_TOKEN_HEADERS": " X-API-Key , ,Authorization,"}, expected: []string{"X-API-Key", "Authorization"}},
+		{name: "Only separators", env: map[string]string{"RATE_LIMIT_TOKEN_HEADERS": " , "}, expected: []string{"API_KEY"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			cfg, err := Load()
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if !slices.Equal(cfg.RateLimitTokenHeaders, tt.expected) {
+				t.Errorf("RateLimitTokenHeaders = %v, expected %v", cfg.RateLimitTokenHeaders, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseTiers(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected map[string]Tier
+		err      string
+	}{
+		{name: "Empty", value: "", expected: map[string]Tier{}},
+		{name: "Multiple tiers", value: "free:10:300, gold:1000:60,", expected: map[string]Tier{
+			"free": {RequestsPerSecond: 10, BlockDurationSeconds: 300},
+			"gold": {RequestsPerSecond: 1000, BlockDurationSeconds: 60},
+		}},
+		{name: "Missing block duration", value: "free:10", err: "expected name:requests:block_seconds"},
+		{name: "Missing name", value: ":10:300", err: "expected name:requests:block_seconds"},
+		{name: "Invalid requests", value: "free:ten:300", err: `invalid requests per second for tier "free"`},
+		{name: "Invalid block duration", value: "free:10:5m", err: `invalid block duration for tier "free"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tiers, err := parseTiers(tt.value)
+			checkParse(t, tiers, err, tt.expected, tt.err)
+		})
+	}
+}
+
+func TestParseShadowRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected map[string]Tier
+		err      string
+	}{
+		{name: "Empty", value: "", expected: map[string]Tier{}},
+		{name: "Multiple rules", value: "ip=5:60, token:free=20:300", expected: map[string]Tier{
+			"ip":         {RequestsPerSecond: 5, BlockDurationSeconds: 60},
+			"token:free": {RequestsPerSecond: 20, BlockDurationSeconds: 300},
+		}},
+		{name: "Rule without limits", value: "ip", err: "expected rule=requests:block_seconds"},
+		{name: "Missing block duration", value: "ip=5", err: "expected rule=requests:block_seconds"},
+		{name: "Missing rule", value: "=5:60", err: "expected rule=requests:block_seconds"},
+		{name: "Invalid requests", value: "ip=five:60", err: `invalid requests per second for shadow rule "ip"`},
+		{name: "Invalid block duration", value: "ip=5:1m", err: `invalid block duration for shadow rule "ip"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := parseShadowRules(tt.value)
+			checkParse(t, rules, err, tt.expected, tt.err)
+		})
+	}
+}
+
+func TestParseRuleStatusCodes(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected map[string]int
+		err      string
+	}{
+		{name: "Empty", value: "", expected: map[string]int{}},
+		{name: "Multiple rules", value: "token:free=503, ip=429", expected: map[string]int{"token:free": 503, "ip": 429}},
+		{name: "Missing status", value: "ip", err: "expected rule=status"},
+		{name: "Missing rule", value: "=503", err: "expected rule=status"},
+		{name: "Invalid status", value: "ip=too-many", err: `invalid rejection status for rule "ip"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statusCodes, err := parseRuleStatusCodes(tt.value)
+			checkParse(t, statusCodes, err, tt.expected, tt.err)
+		})
+	}
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []string
+		expected []netip.Prefix
+		err      string
+	}{
+		{name: "Empty", values: nil, expected: nil},
+		{name: "Networks and addresses", values: []string{"10.0.0.0/8", "192.168.1.10", "2001:db8::/32"}, expected: []netip.Prefix{
+			netip.MustParsePrefix("10.0.0.0/8"),
+			netip.MustParsePrefix("192.168.1.10/32"),
+			netip.MustParsePrefix("2001:db8::/32"),
+		}},
+		{name: "IPv4-mapped address", values: []string{"::ffff:10.0.0.1"}, expected: []netip.Prefix{netip.MustParsePrefix("10.0.0.1/32")}},
+		{name: "Network with host bits", values: []string{"10.1.2.3/8"}, expected: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}},
+		{name: "Invalid value", values: []string{"proxy.internal"}, err: `invalid trusted proxy "proxy.internal"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prefixes, err := parseTrustedProxies(tt.values)
+			checkParse(t, prefixes, err, tt.expected, tt.err)
+		})
+	}
+}
+
+// checkParse compara o resultado de um parser com o valor esperado ou, se
+// expectedErr não for vazio, verifica que o erro contém a mensagem esperada
+func checkParse[T any](t *testing.T, got T, err error, expected T, expectedErr string) {
+	t.Helper()
+	if expectedErr != "" {
+		if err == nil || !strings.Contains(err.Error(), expectedErr) {
+			t.Errorf("error = %v, expected it to contain %q", err, expectedErr)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("unexpected error = %v", err)
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("result = %v, expected %v", got, expected)
+	}
+}
+
+func TestWarnings(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   Config
+		expected int
+	}{
+		{name: "API_KEY only", config: Config{RateLimitTokenHeaders: []string{"API_KEY"}}, expected: 0},
+		{name: "Authorization without validation", config: Config{RateLimitTokenHeaders: []string{"API_KEY", "authorization"}}, expected: 1},
+		{name: "Authorization with key store", config: Config{RateLimitTokenHeaders: []string{"Authorization"}, KeyStoreType: "redis"}, expected: 0},
+		{name: "Authorization with JWT", config: Config{RateLimitTokenHeaders: []string{"Authorization"}, JWTJWKSFile: "jwks.json"}, expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if warnings := tt.config.Warnings(); len(warnings) != tt.expected {
+				t.Errorf("Warnings() = %v, expected %d warnings", warnings, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/m4rcelotoledo/rate-limiter/pkg/access"
+	"github.com/m4rcelotoledo/rate-limiter/pkg/keystore"
+	"github.com/m4rcelotoledo/rate-limiter/pkg/middleware"
+)
+
+// Validate verifica os valores da configuração e os arquivos referenciados
+// por ela (mensagens e template de rejeição, listas de acesso, key store e
+// JWKS), retornando todos os problemas encontrados
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(valid bool, format string, args ...any) {
+		if !valid {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.RateLimitIPRequestsPerSecond > 0, "RATE_LIMIT_IP_REQUESTS_PER_SECOND must be positive: %d", c.RateLimitIPRequestsPerSecond)
+	check(c.RateLimitIPBlockDurationSeconds > 0, "RATE_LIMIT_IP_BLOCK_DURATION_SECONDS must be positive: %d", c.RateLimitIPBlockDurationSeconds)
+	check(c.RateLimitTokenRequestsPerSecond > 0, "RATE_LIMIT_TOKEN_REQUESTS_PER_SECOND must be positive: %d", c.RateLimitTokenRequestsPerSecond)
+	check(c.RateLimitTokenBlockDurationSeconds > 0, "RATE_LIMIT_TOKEN_BLOCK_DURATION_SECONDS must be positive: %d", c.RateLimitTokenBlockDurationSeconds)
+	check(c.RateLimitIPv4PrefixLength >= 0 && c.RateLimitIPv4PrefixLength <= 32, "RATE_LIMIT_IPV4_PREFIX_LENGTH must be between 0 and 32: %d", c.RateLimitIPv4PrefixLength)
+	check(c.RateLimitIPv6PrefixLength >= 0 && c.RateLimitIPv6PrefixLength <= 128, "RATE_LIMIT_IPV6_PREFIX_LENGTH must be between 0 and 128: %d", c.RateLimitIPv6PrefixLength)
+	check(c.RateLimitTokenHashSecret != "", "RATE_LIMIT_TOKEN_HASH_SECRET is required: without it, API keys are stored in plain text in the storage keys")
+	for name, tier := range c.RateLimitTiers {
+		check(tier.RequestsPerSecond > 0 && tier.BlockDurationSeconds > 0, "RATE_LIMIT_TIERS: tier %q must have positive requests and block duration", name)
+	}
+	for rule, candidate := range c.RateLimitShadowRules {
+		tier, isTierRule := strings.CutPrefix(rule, "token:")
+		_, tierExists := c.RateLimitTiers[tier]
+		check(rule == "ip" || rule == "token" || (isTierRule && tierExists), "RATE_LIMIT_SHADOW_RULES: unknown rule %q", rule)
+		check(candidate.RequestsPerSecond > 0 && candidate.BlockDurationSeconds > 0, "RATE_LIMIT_SHADOW_RULES: rule %q must have positive requests and block duration", rule)
+	}
+
+	if _, err := middleware.ParseHeaderMode(c.RateLimitHeaders); err != nil {
+		errs = append(errs, err)
+	}
+	check(middleware.ValidRejectionStatus(c.RejectionStatusCode), "RATE_LIMIT_REJECTION_STATUS must be between 400 and 599: %d", c.RejectionStatusCode)
+	for rule, statusCode := range c.RejectionRuleStatusCodes {
+		check(middleware.ValidRejectionStatus(statusCode), "RATE_LIMIT_REJECTION_RULE_STATUS: status for rule %q must be between 400 and 599: %d", rule, statusCode)
+	}
+	// Os status já foram verificados acima; o handler é criado com os padrões
+	// apenas para validar formato, mensagens e template sem repetir os erros
+	rejectionConfig := *c
+	rejectionConfig.RejectionStatusCode, rejectionConfig.RejectionRuleStatusCodes = 0, nil
+	if _, err := rejectionConfig.RejectionHandler(); err != nil {
+		errs = append(errs, err)
+	}
+
+	switch c.KeyStoreType {
+	case "", "redis":
+	case "file":
+		if _, err := keystore.LoadStaticFile(c.KeyStoreFile); err != nil {
+			errs = append(errs, err)
+		}
+	case "hmac":
+		check(c.KeyStoreHMACSecret != "", "KEY_STORE_HMAC_SECRET is required for the hmac key store")
+	default:
+		errs = append(errs, fmt.Errorf("invalid key store type: %s", c.KeyStoreType))
+	}
+	check(c.JWTLeewaySeconds >= 0, "JWT_LEEWAY_SECONDS must not be negative: %d", c.JWTLeewaySeconds)
+	if _, err := c.JWTVerifier(); err != nil {
+		errs = append(errs, err)
+	}
+	check(c.MetricsBlockCountIntervalSeconds >= 0, "METRICS_BLOCK_COUNT_INTERVAL_SECONDS must not be negative: %d", c.MetricsBlockCountIntervalSeconds)
+	if c.AccessListFile != "" {
+		if _, err := access.LoadFile(c.AccessListFile); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	var level slog.Level
+	check(level.UnmarshalText([]byte(c.LogLevel)) == nil, "invalid log level: %s", c.LogLevel)
+	check(slices.Contains([]string{"json", "text"}, c.LogFormat), "invalid log format: %s", c.LogFormat)
+	check(c.LogAllowedSampleRate >= 0 && c.LogAllowedSampleRate <= 1, "LOG_ALLOWED_SAMPLE_RATE must be between 0 and 1: %g", c.LogAllowedSampleRate)
+	check(slices.Contains([]string{"", "otlp", "stdout"}, c.TracingExporter), "invalid tracing exporter: %s", c.TracingExporter)
+	check(c.TracingSampleRatio >= 0 && c.TracingSampleRatio <= 1, "TRACING_SAMPLE_RATIO must be between 0 and 1: %g", c.TracingSampleRatio)
+
+	check(slices.Contains([]string{"", "stdout", "file", "redis", "webhook"}, c.AuditSink), "invalid audit sink: %s", c.AuditSink)
+	check(c.AuditSink != "webhook" || c.AuditWebhookURL != "", "AUDIT_WEBHOOK_URL is required for the webhook audit sink")
+	check(c.AuditQueueSize > 0, "AUDIT_QUEUE_SIZE must be positive: %d", c.AuditQueueSize)
+
+	check(c.HeavyHittersRetentionMinutes > 0, "HEAVY_HITTERS_RETENTION_MINUTES must be positive: %d", c.HeavyHittersRetentionMinutes)
+	check(c.HeavyHittersCapacity > 0, "HEAVY_HITTERS_CAPACITY must be positive: %d", c.HeavyHittersCapacity)
+
+	return errors.Join(errs...)
+}
+
+// Warnings retorna configurações aceitas, mas provavelmente indesejadas, que
+// o servidor registra ao iniciar e o comando validate mostra
+func (c *Config) Warnings() []string {
+	var warnings []string
+
+	// Sem validação, credenciais de outros serviços enviadas em Authorization
+	// ganham, cada uma, um limite por token próprio
+	readsAuthorization := slices.ContainsFunc(c.RateLimitTokenHeaders, func(name string) bool {
+		return strings.EqualFold(name, "Authorization")
+	})
+	if readsAuthorization && !c.validatesTokens() {
+		warnings = append(warnings, "RATE_LIMIT_TOKEN_HEADERS: Authorization is read without a key store (KEY_STORE_TYPE) or JWT verification (JWT_HMAC_SECRET or JWT_JWKS_FILE); every bearer credential gets its own token limit")
+	}
+	return warnings
+}
@@ -0,0 +1,72 @@
+package limitertest
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// AdapterRequest é uma linha da tabela de conformidade dos adapters: a
+// requisição enviada e a resposta esperada
+type AdapterRequest struct {
+	Name               string
+	ClientIP           string
+	Token              string
+	ExpectedStatus     int
+	ExpectedRemaining  string
+	ExpectedBody       string
+	ExpectedRetryAfter bool
+}
+
+// AdapterRequests é a sequência que todo adapter HTTP deve responder da mesma
+// forma sobre um rate limiter criado por NewRateLimiter. As linhas dependem
+// umas das outras e são executadas em ordem.
+var AdapterRequests = []AdapterRequest{
+	{Name: "IP within limit", ClientIP: "192.168.1.1", ExpectedStatus: http.StatusOK, ExpectedRemaining: "1", ExpectedBody: "ok"},
+	{Name: "IP at limit", ClientIP: "192.168.1.1", ExpectedStatus: http.StatusOK, ExpectedRemaining: "0", ExpectedBody: "ok"},
+	{Name: "IP over limit", ClientIP: "192.168.1.1", ExpectedStatus: http.StatusTooManyRequests, ExpectedRemaining: "0", ExpectedBody: "maximum number of requests", ExpectedRetryAfter: true},
+	{Name: "Token has its own limit", ClientIP: "192.168.1.1", Token: "live-key", ExpectedStatus: http.StatusOK, ExpectedRemaining: "2", ExpectedBody: "ok"},
+	{Name: "Denied IP", ClientIP: "203.0.113.9", ExpectedStatus: http.StatusForbidden, ExpectedBody: "access denied"},
+	{Name: "Allowed token is exempt", ClientIP: "192.168.1.1", Token: "internal-key", ExpectedStatus: http.StatusOK, ExpectedBody: "ok"},
+	{Name: "Unknown token", ClientIP: "192.168.1.2", Token: "unknown-key", ExpectedStatus: http.StatusUnauthorized, ExpectedBody: "invalid API key"},
+}
+
+// RunAdapterConformance executa AdapterRequests contra um adapter cuja rota
+// "/" responde "ok". serve entrega a requisição ao adapter e devolve a
+// resposta. O IP do cliente vai em RemoteAddr e em X-Forwarded-For, para
+// adapters que só o recebem através de um proxy confiável.
+func RunAdapterConformance(t *testing.T, serve func(t *testing.T, req *http.Request) *http.Response) {
+	t.Helper()
+	for _, tt := range AdapterRequests {
+		t.Run(tt.Name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.ClientIP + ":1234"
+			req.Header.Set("X-Forwarded-For", tt.ClientIP)
+			if tt.Token != "" {
+				req.Header.Set("API_KEY", tt.Token)
+			}
+
+			resp := serve(t, req)
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatalf("failed to read body: %v", err)
+			}
+
+			if resp.StatusCode != tt.ExpectedStatus {
+				t.Errorf("status = %d, expected %d", resp.StatusCode, tt.ExpectedStatus)
+			}
+			if !strings.Contains(string(body), tt.ExpectedBody) {
+				t.Errorf("body = %q, expected to contain %q", body, tt.ExpectedBody)
+			}
+			if got := resp.Header.Get("X-RateLimit-Remaining"); got != tt.ExpectedRemaining {
+				t.Errorf("X-RateLimit-Remaining = %q, expected %q", got, tt.ExpectedRemaining)
+			}
+			if got := resp.Header.Get("Retry-After"); (got != "") != tt.ExpectedRetryAfter {
+				t.Errorf("Retry-After = %q, expected present: %v", got, tt.ExpectedRetryAfter)
+			}
+		})
+	}
+}
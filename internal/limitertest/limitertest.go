@@ -0,0 +1,41 @@
+// Package limitertest monta o rate limiter em memória usado nos testes do
+// middleware HTTP e dos adapters, junto com a tabela de conformidade que todo
+// adapter HTTP executa
+package limitertest
+
+import (
+	"testing"
+
+	"github.com/m4rcelotoledo/rate-limiter/pkg/access"
+	"github.com/m4rcelotoledo/rate-limiter/pkg/keystore"
+	"github.com/m4rcelotoledo/rate-limiter/pkg/limiter"
+	"github.com/m4rcelotoledo/rate-limiter/pkg/storage"
+)
+
+// NewRateLimiter cria um rate limiter sobre MemoryStorage com 2 req/s por IP,
+// 3 req/s por token e bloqueio de 60 segundos. A rede 203.0.113.0/24 é negada,
+// o token "internal-key" é liberado e apenas "live-key" e "internal-key" são
+// aceitos pelo key store. As opções ajustam essa configuração. O storage é
+// fechado no fim do teste.
+func NewRateLimiter(t testing.TB, opts ...limiter.Option) *limiter.RateLimiter {
+	t.Helper()
+	config := &limiter.Config{
+		IPRequestsPerSecond:       2,
+		IPBlockDurationSeconds:    60,
+		TokenRequestsPerSecond:    3,
+		TokenBlockDurationSeconds: 60,
+		RejectUnknownTokens:       true,
+	}
+
+	list := access.NewList()
+	list.Add(access.Deny, access.Rules{IPs: []string{"203.0.113.0/24"}})
+	list.Add(access.Allow, access.Rules{Tokens: []string{"internal-key"}})
+
+	opts = append([]limiter.Option{
+		limiter.WithAccessList(list),
+		limiter.WithKeyStore(keystore.NewStaticStore("live-key", "internal-key")),
+	}, opts...)
+	memoryStorage := storage.NewMemoryStorage()
+	t.Cleanup(func() { memoryStorage.Close() })
+	return limiter.NewRateLimiter(memoryStorage, config, opts...)
+}
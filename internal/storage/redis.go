@@ -1,73 +0,0 @@
-package storage
-
-import (
-	"context"
-	"fmt"
-	"time"
-
-	"github.com/go-redis/redis/v8"
-)
-
-type RedisStorage struct {
-	client *redis.Client
-}
-
-func NewRedisStorage(host, port, password string, db int) (*RedisStorage, error) {
-	client := redis.NewClient(&redis.Options{
-		Addr:     fmt.Sprintf("%s:%s", host, port),
-		Password: password,
-		DB:       db,
-	})
-
-	// Testa a conexão
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	if err := client.Ping(ctx).Err(); err != nil {
-		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
-	}
-
-	return &RedisStorage{client: client}, nil
-}
-
-func (r *RedisStorage) Increment(ctx context.Context, key string, expiration time.Duration) (int64, error) {
-	pipe := r.client.Pipeline()
-
-	// Incrementa o contador
-	incr := pipe.Incr(ctx, key)
-
-	// Define a expiração se a chave não existir
-	pipe.Expire(ctx, key, expiration)
-
-	_, err := pipe.Exec(ctx)
-	if err != nil {
-		return 0, err
-	}
-
-	return incr.Val(), nil
-}
-
-func (r *RedisStorage) Get(ctx context.Context, key string) (int64, error) {
-	val, err := r.client.Get(ctx, key).Int64()
-	if err == redis.Nil {
-		return 0, nil
-	}
-	return val, err
-}
-
-func (r *RedisStorage) Set(ctx context.Context, key string, value int64, expiration time.Duration) error {
-	return r.client.Set(ctx, key, value, expiration).Err()
-}
-
-func (r *RedisStorage) Exists(ctx context.Context, key string) (bool, error) {
-	result, err := r.client.Exists(ctx, key).Result()
-	return result > 0, err
-}
-
-func (r *RedisStorage) Delete(ctx context.Context, key string) error {
-	return r.client.Del(ctx, key).Err()
-}
-
-func (r *RedisStorage) Close() error {
-	return r.client.Close()
-}
@@ -0,0 +1,266 @@
+// Package access implementa as listas de IPs/CIDRs e tokens permitidos e
+// bloqueados, consultadas antes da verificação de limite.
+package access
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Decision representa o resultado da consulta às listas de acesso
+type Decision int
+
+const (
+	// None indica que o cliente não está em nenhuma lista e segue o rate limit normal
+	None Decision = iota
+	// Allow indica que o cliente está na allowlist e ignora o rate limit
+	Allow
+	// Deny indica que o cliente está na denylist e deve ser rejeitado
+	Deny
+)
+
+func (d Decision) String() string {
+	switch d {
+	case Allow:
+		return "allow"
+	case Deny:
+		return "deny"
+	default:
+		return "none"
+	}
+}
+
+// Rules agrupa IPs/CIDRs e tokens de uma lista. É também o formato usado no
+// arquivo de configuração e na API de administração.
+type Rules struct {
+	IPs    []string `json:"ips"`
+	Tokens []string `json:"tokens"`
+}
+
+// Entries representa o conteúdo completo das listas de acesso
+type Entries struct {
+	Allow Rules `json:"allow"`
+	Deny  Rules `json:"deny"`
+}
+
+type set struct {
+	networks map[netip.Prefix]struct{}
+	tokens   map[string]struct{}
+}
+
+func newSet() *set {
+	return &set{
+		networks: make(map[netip.Prefix]struct{}),
+		tokens:   make(map[string]struct{}),
+	}
+}
+
+// List mantém a allowlist e a denylist, seguras para uso concorrente e
+// alteráveis em tempo de execução.
+type List struct {
+	mu    sync.RWMutex
+	allow *set
+	deny  *set
+}
+
+func NewList() *List {
+	return &List{
+		allow: newSet(),
+		deny:  newSet(),
+	}
+}
+
+// LoadFile cria uma lista a partir de um arquivo JSON no formato de Entries
+func LoadFile(path string) (*List, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read access list file: %w", err)
+	}
+
+	var entries Entries
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse access list file: %w", err)
+	}
+
+	list := NewList()
+	if err := list.Add(Allow, entries.Allow); err != nil {
+		return nil, err
+	}
+	if err := list.Add(Deny, entries.Deny); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// Check consulta as listas para um IP e um token (ambos opcionais). A denylist
+// tem precedência sobre a allowlist.
+func (l *List) Check(ip, token string) Decision {
+	addr, err := netip.ParseAddr(ip)
+	hasIP := err == nil
+	if hasIP {
+		addr = addr.Unmap()
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if (hasIP && l.deny.containsIP(addr)) || l.deny.containsToken(token) {
+		return Deny
+	}
+	if (hasIP && l.allow.containsIP(addr)) || l.allow.containsToken(token) {
+		return Allow
+	}
+	return None
+}
+
+// Add adiciona IPs/CIDRs e tokens à lista indicada. Nenhuma entrada é
+// adicionada se alguma delas for inválida.
+func (l *List) Add(decision Decision, rules Rules) error {
+	networks, err := parseNetworks(rules.IPs)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	target, err := l.set(decision)
+	if err != nil {
+		return err
+	}
+	for _, network := range networks {
+		target.networks[network] = struct{}{}
+	}
+	for _, token := range rules.Tokens {
+		if token = strings.TrimSpace(token); token != "" {
+			target.tokens[token] = struct{}{}
+		}
+	}
+
+	return nil
+}
+
+// Remove retira IPs/CIDRs e tokens da lista indicada
+func (l *List) Remove(decision Decision, rules Rules) error {
+	networks, err := parseNetworks(rules.IPs)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	target, err := l.set(decision)
+	if err != nil {
+		return err
+	}
+	for _, network := range networks {
+		delete(target.networks, network)
+	}
+	for _, token := range rules.Tokens {
+		delete(target.tokens, strings.TrimSpace(token))
+	}
+
+	return nil
+}
+
+// Entries retorna uma cópia ordenada do conteúdo das listas
+func (l *List) Entries() Entries {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return Entries{
+		Allow: l.allow.rules(),
+		Deny:  l.deny.rules(),
+	}
+}
+
+// ParseDecision converte o nome de uma lista ("allow" ou "deny") em Decision
+func ParseDecision(name string) (Decision, error) {
+	switch name {
+	case "allow":
+		return Allow, nil
+	case "deny":
+		return Deny, nil
+	default:
+		return None, fmt.Errorf("invalid access list: %s", name)
+	}
+}
+
+func (l *List) set(decision Decision) (*set, error) {
+	switch decision {
+	case Allow:
+		return l.allow, nil
+	case Deny:
+		return l.deny, nil
+	default:
+		return nil, fmt.Errorf("invalid access list: %s", decision)
+	}
+}
+
+func (s *set) containsIP(addr netip.Addr) bool {
+	for network := range s.networks {
+		if network.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *set) containsToken(token string) bool {
+	if token == "" {
+		return false
+	}
+	_, exists := s.tokens[token]
+	return exists
+}
+
+func (s *set) rules() Rules {
+	rules := Rules{
+		IPs:    make([]string, 0, len(s.networks)),
+		Tokens: make([]string, 0, len(s.tokens)),
+	}
+	for network := range s.networks {
+		rules.IPs = append(rules.IPs, network.String())
+	}
+	for token := range s.tokens {
+		rules.Tokens = append(rules.Tokens, token)
+	}
+	sort.Strings(rules.IPs)
+	sort.Strings(rules.Tokens)
+	return rules
+}
+
+// parseNetworks aceita tanto CIDRs (10.0.0.0/8) quanto IPs individuais
+func parseNetworks(values []string) ([]netip.Prefix, error) {
+	networks := make([]netip.Prefix, 0, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		if strings.Contains(value, "/") {
+			network, err := netip.ParsePrefix(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid CIDR %q: %w", value, err)
+			}
+			networks = append(networks, network.Masked())
+			continue
+		}
+
+		addr, err := netip.ParseAddr(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid IP %q: %w", value, err)
+		}
+		addr = addr.Unmap()
+		networks = append(networks, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return networks, nil
+}
@@ -0,0 +1,103 @@
+package access
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestList_Check(t *testing.T) {
+	list := NewList()
+	if err := list.Add(Allow, Rules{
+		IPs:    []string{"10.0.0.0/8", "192.168.1.10", "2001:db8::/32"},
+		Tokens: []string{"health-checker"},
+	}); err != nil {
+		t.Fatalf("Add(Allow) error = %v", err)
+	}
+	if err := list.Add(Deny, Rules{
+		IPs:    []string{"10.66.0.0/16", "203.0.113.7"},
+		Tokens: []string{"leaked-key"},
+	}); err != nil {
+		t.Fatalf("Add(Deny) error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		ip       string
+		token    string
+		expected Decision
+	}{
+		{name: "IP in allowed CIDR", ip: "10.1.2.3", expected: Allow},
+		{name: "Allowed single IP", ip: "192.168.1.10", expected: Allow},
+		{name: "IPv6 in allowed CIDR", ip: "2001:db8::1", expected: Allow},
+		{name: "IPv4-mapped IPv6 in allowed CIDR", ip: "::ffff:10.1.2.3", expected: Allow},
+		{name: "Allowed token", ip: "172.16.0.1", token: "health-checker", expected: Allow},
+		{name: "Denied single IP", ip: "203.0.113.7", expected: Deny},
+		{name: "Deny takes precedence over allow", ip: "10.66.1.1", expected: Deny},
+		{name: "Denied token from allowed IP", ip: "10.1.2.3", token: "leaked-key", expected: Deny},
+		{name: "Unlisted client", ip: "172.16.0.1", token: "other-key", expected: None},
+		{name: "Invalid IP", ip: "unknown", expected: None},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := list.Check(tt.ip, tt.token)
+			if result != tt.expected {
+				t.Errorf("Check(%q, %q) = %v, expected %v", tt.ip, tt.token, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestList_AddRemove(t *testing.T) {
+	list := NewList()
+
+	if err := list.Add(Deny, Rules{IPs: []string{"not-an-ip"}}); err == nil {
+		t.Errorf("Add() with invalid IP should return an error")
+	}
+
+	if err := list.Add(Deny, Rules{IPs: []string{"198.51.100.0/24"}, Tokens: []string{"bad"}}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if result := list.Check("198.51.100.20", ""); result != Deny {
+		t.Errorf("Check() = %v, expected %v", result, Deny)
+	}
+
+	if err := list.Remove(Deny, Rules{IPs: []string{"198.51.100.0/24"}, Tokens: []string{"bad"}}); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if result := list.Check("198.51.100.20", "bad"); result != None {
+		t.Errorf("Check() after Remove() = %v, expected %v", result, None)
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "access.json")
+	content := `{
+		"allow": {"ips": ["10.0.0.0/8"], "tokens": ["partner-key"]},
+		"deny": {"ips": ["203.0.113.0/24"], "tokens": []}
+	}`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	list, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+
+	entries := list.Entries()
+	if len(entries.Allow.IPs) != 1 || entries.Allow.IPs[0] != "10.0.0.0/8" {
+		t.Errorf("Entries().Allow.IPs = %v", entries.Allow.IPs)
+	}
+	if len(entries.Allow.Tokens) != 1 || entries.Allow.Tokens[0] != "partner-key" {
+		t.Errorf("Entries().Allow.Tokens = %v", entries.Allow.Tokens)
+	}
+	if result := list.Check("203.0.113.9", ""); result != Deny {
+		t.Errorf("Check() = %v, expected %v", result, Deny)
+	}
+
+	if _, err := LoadFile(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Errorf("LoadFile() with missing file should return an error")
+	}
+}
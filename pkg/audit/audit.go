@@ -0,0 +1,60 @@
+// Package audit registra o ciclo de vida dos bloqueios (bloqueio e
+// desbloqueio de identificadores) em um destino configurável, com entrega
+// best-effort.
+package audit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
+
+// EventType identifica o tipo de evento de auditoria
+type EventType string
+
+const (
+	// BlockEvent indica que um identificador foi bloqueado
+	BlockEvent EventType = "block"
+	// UnblockEvent indica que o bloqueio de um identificador foi removido
+	UnblockEvent EventType = "unblock"
+)
+
+// Origens dos eventos
+const (
+	// SourceLimiter indica bloqueios criados pelo limite excedido
+	SourceLimiter = "limiter"
+	// SourceAdmin indica ações da API de administração
+	SourceAdmin = "admin"
+)
+
+// Event é um registro de auditoria. O ID permite descartar duplicatas, já que
+// lotes que falham são reenviados.
+type Event struct {
+	ID        string    `json:"id"`
+	Type      EventType `json:"type"`
+	Time      time.Time `json:"time"`
+	Source    string    `json:"source"`
+	LimitType string    `json:"limit_type"`
+	// Identifier é o identificador como aparece nas chaves do storage (IPs
+	// agregados, tokens com hash); tokens originais não são registrados
+	Identifier string `json:"identifier"`
+	Rule       string `json:"rule,omitempty"`
+	// Duration é a duração do bloqueio; zero para bloqueios sem expiração
+	Duration  time.Duration `json:"duration,omitempty"`
+	ExpiresAt *time.Time    `json:"expires_at,omitempty"`
+	Reason    string        `json:"reason,omitempty"`
+}
+
+// Sink é o destino dos eventos. Write recebe lotes em ordem e deve retornar
+// erro se algum evento do lote não foi persistido; o lote inteiro é reenviado.
+type Sink interface {
+	Write(ctx context.Context, events []Event) error
+	Close() error
+}
+
+func newEventID() string {
+	var id [16]byte
+	rand.Read(id[:])
+	return hex.EncodeToString(id[:])
+}
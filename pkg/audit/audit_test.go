@@ -0,0 +1,348 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/m4rcelotoledo/rate-limiter/pkg/metrics"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// memorySink guarda os eventos recebidos e falha nas primeiras chamadas
+type memorySink struct {
+	mu       sync.Mutex
+	failures int
+	calls    int
+	events   []Event
+	block    chan struct{}
+}
+
+func (s *memorySink) Write(ctx context.Context, events []Event) error {
+	if s.block != nil {
+		<-s.block
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	if s.calls <= s.failures {
+		return errors.New("sink unavailable")
+	}
+	s.events = append(s.events, events...)
+	return nil
+}
+
+func (s *memorySink) Close() error {
+	return nil
+}
+
+func (s *memorySink) received() []Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Event(nil), s.events...)
+}
+
+func (s *memorySink) callCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls
+}
+
+func closeEmitter(t *testing.T, emitter *Emitter) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := emitter.Close(ctx); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+}
+
+func TestEmitter_RetriesUntilDelivered(t *testing.T) {
+	sink := &memorySink{failures: 2}
+	emitter := NewEmitter(sink, WithFlushInterval(10*time.Millisecond), WithMaxBackoff(20*time.Millisecond))
+
+	for _, identifier := range []string{"192.168.1.1", "192.168.1.2", "192.168.1.3"} {
+		if err := emitter.Emit(context.Background(), Event{Type: BlockEvent, LimitType: "ip", Identifier: identifier}); err != nil {
+			t.Fatalf("Emit() error = %v", err)
+		}
+	}
+	closeEmitter(t, emitter)
+
+	events := sink.received()
+	if len(events) != 3 {
+		t.Fatalf("received %d events, expected 3", len(events))
+	}
+	for i, identifier := range []string{"192.168.1.1", "192.168.1.2", "192.168.1.3"} {
+		if events[i].Identifier != identifier {
+			t.Errorf("event %d identifier = %q, expected %q", i, events[i].Identifier, identifier)
+		}
+		if events[i].ID == "" || events[i].Time.IsZero() {
+			t.Errorf("event %d missing ID or time: %+v", i, events[i])
+		}
+	}
+	if sink.calls < 3 {
+		t.Errorf("sink calls = %d, expected retries after failures", sink.calls)
+	}
+}
+
+func TestEmitter_FullQueueDropsWithoutBlocking(t *testing.T) {
+	sink := &memorySink{block: make(chan struct{})}
+	registry := prometheus.NewRegistry()
+	emitter := NewEmitter(sink,
+		WithQueueSize(2),
+		WithBatchSize(1),
+		WithMetrics(metrics.New(registry)),
+	)
+
+	// O primeiro evento fica preso no Sink; os dois seguintes ocupam a fila
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		if err := emitter.Emit(ctx, Event{Type: BlockEvent}); err != nil {
+			t.Fatalf("Emit() %d error = %v", i+1, err)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	start := time.Now()
+	if err := emitter.Emit(ctx, Event{Type: BlockEvent}); !errors.Is(err, ErrDropped) {
+		t.Errorf("Emit() with full queue error = %v, expected %v", err, ErrDropped)
+	}
+	if waited := time.Since(start); waited >= 100*time.Millisecond {
+		t.Errorf("Emit() waited %v with a full queue, expected to drop without waiting", waited)
+	}
+	if dropped := emitter.Dropped(); dropped != 1 {
+		t.Errorf("Dropped() = %d, expected 1", dropped)
+	}
+	expectedMetrics := `
+# HELP rate_limiter_audit_events_dropped_total Audit events dropped because the queue was full or delivery failed.
+# TYPE rate_limiter_audit_events_dropped_total counter
+rate_limiter_audit_events_dropped_total 1
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expectedMetrics), "rate_limiter_audit_events_dropped_total"); err != nil {
+		t.Errorf("unexpected metrics: %v", err)
+	}
+
+	close(sink.block)
+	closeEmitter(t, emitter)
+
+	if received := len(sink.received()); received != 3 {
+		t.Errorf("received %d events, expected 3", received)
+	}
+}
+
+func TestEmitter_FailingSink(t *testing.T) {
+	sink := &memorySink{failures: 1 << 30}
+	emitter := NewEmitter(sink,
+		WithQueueSize(1),
+		WithBatchSize(1),
+		WithMaxBackoff(10*time.Millisecond),
+	)
+
+	// O primeiro evento fica em reenvio; o segundo ocupa a fila e o
+	// terceiro é descartado
+	ctx := context.Background()
+	emitter.Emit(ctx, Event{Type: BlockEvent})
+	deadline := time.Now().Add(time.Second)
+	for sink.callCount() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	emitter.Emit(ctx, Event{Type: BlockEvent})
+	if err := emitter.Emit(ctx, Event{Type: BlockEvent}); !errors.Is(err, ErrDropped) {
+		t.Errorf("Emit() with failing sink error = %v, expected %v", err, ErrDropped)
+	}
+
+	// Close desiste dos reenvios quando o contexto expira
+	closeCtx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := emitter.Close(closeCtx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Close() error = %v, expected %v", err, context.DeadlineExceeded)
+	}
+	select {
+	case <-emitter.done:
+	case <-time.After(time.Second):
+		t.Fatalf("delivery goroutine still running after Close() expired")
+	}
+	if dropped := emitter.Dropped(); dropped != 3 {
+		t.Errorf("Dropped() = %d, expected 3", dropped)
+	}
+}
+
+func TestEmitter_EmitDuringClose(t *testing.T) {
+	sink := &memorySink{}
+	emitter := NewEmitter(sink, WithQueueSize(10), WithBatchSize(5))
+
+	const emitters, events = 8, 50
+	var wg sync.WaitGroup
+	for i := 0; i < emitters; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < events; j++ {
+				emitter.Emit(context.Background(), Event{Type: BlockEvent})
+			}
+		}()
+	}
+	time.Sleep(time.Millisecond)
+	closeEmitter(t, emitter)
+	wg.Wait()
+
+	// Todo evento é entregue ou contado como descartado
+	if total := int64(len(sink.received())) + emitter.Dropped(); total != emitters*events {
+		t.Errorf("received + dropped = %d, expected %d", total, emitters*events)
+	}
+	if err := emitter.Emit(context.Background(), Event{Type: BlockEvent}); !errors.Is(err, ErrDropped) {
+		t.Errorf("Emit() after Close() error = %v, expected %v", err, ErrDropped)
+	}
+}
+
+func TestEmitter_Nil(t *testing.T) {
+	var emitter *Emitter
+	if err := emitter.Emit(context.Background(), Event{Type: BlockEvent}); err != nil {
+		t.Errorf("Emit() on nil emitter error = %v", err)
+	}
+	if err := emitter.Close(context.Background()); err != nil {
+		t.Errorf("Close() on nil emitter error = %v", err)
+	}
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	sink, err := NewFileSink(path)
+	if err != nil {
+		t.Fatalf("NewFileSink() error = %v", err)
+	}
+
+	events := []Event{
+		{ID: "1", Type: BlockEvent, LimitType: "ip", Identifier: "192.168.1.1", Duration: time.Minute},
+		{ID: "2", Type: UnblockEvent, LimitType: "ip", Identifier: "192.168.1.1"},
+	}
+	if err := sink.Write(context.Background(), events); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open audit file: %v", err)
+	}
+	defer file.Close()
+
+	var lines []Event
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("invalid JSON line %q: %v", scanner.Text(), err)
+		}
+		lines = append(lines, event)
+	}
+
+	if len(lines) != 2 || lines[0].Type != BlockEvent || lines[1].Type != UnblockEvent {
+		t.Errorf("file events = %+v, expected block and unblock", lines)
+	}
+	if lines[0].Duration != time.Minute {
+		t.Errorf("Duration = %v, expected %v", lines[0].Duration, time.Minute)
+	}
+}
+
+func TestWebhookSink(t *testing.T) {
+	var mu sync.Mutex
+	var received []Event
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		var events []Event
+		if err := json.NewDecoder(r.Body).Decode(&events); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received = append(received, events...)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	emitter := NewEmitter(NewWebhookSink(server.URL, time.Second),
+		WithFlushInterval(10*time.Millisecond),
+		WithMaxBackoff(20*time.Millisecond),
+	)
+	if err := emitter.Emit(context.Background(), Event{Type: BlockEvent, Identifier: "203.0.113.7"}); err != nil {
+		t.Fatalf("Emit() error = %v", err)
+	}
+	closeEmitter(t, emitter)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if attempts != 2 {
+		t.Errorf("webhook attempts = %d, expected 2 (one failure and one retry)", attempts)
+	}
+	if len(received) != 1 || received[0].Identifier != "203.0.113.7" {
+		t.Errorf("webhook events = %+v, expected the block of 203.0.113.7", received)
+	}
+}
+
+func TestRedisStreamSink(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	// O stream mantém apenas os 2 eventos mais recentes
+	sink := NewRedisStreamSink(client, "rate_limiter:audit", 2)
+	ctx := context.Background()
+
+	events := []Event{
+		{ID: "1", Type: BlockEvent, LimitType: "ip", Identifier: "192.168.1.1", Duration: time.Minute},
+		{ID: "2", Type: UnblockEvent, LimitType: "ip", Identifier: "192.168.1.1"},
+		{ID: "3", Type: BlockEvent, LimitType: "token", Identifier: "sub:user-42"},
+	}
+	if err := sink.Write(ctx, events); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	messages, err := client.XRange(ctx, "rate_limiter:audit", "-", "+").Result()
+	if err != nil {
+		t.Fatalf("XRange() error = %v", err)
+	}
+	var stream []Event
+	for _, message := range messages {
+		var event Event
+		if err := json.Unmarshal([]byte(message.Values["event"].(string)), &event); err != nil {
+			t.Fatalf("invalid event field %v: %v", message.Values["event"], err)
+		}
+		if message.Values["id"] != event.ID {
+			t.Errorf("id field = %v, expected %q", message.Values["id"], event.ID)
+		}
+		stream = append(stream, event)
+	}
+	if len(stream) != 2 || stream[0].ID != "2" || stream[1].Identifier != "sub:user-42" {
+		t.Errorf("stream events = %+v, expected the last 2 events", stream)
+	}
+
+	server.Close()
+	if err := sink.Write(ctx, events[:1]); err == nil {
+		t.Error("Write() should return an error when Redis is unavailable")
+	}
+}
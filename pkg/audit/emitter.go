@@ -0,0 +1,268 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/m4rcelotoledo/rate-limiter/pkg/metrics"
+)
+
+// ErrDropped indica que o evento foi descartado porque a fila estava cheia ou
+// o emitter foi fechado
+var ErrDropped = errors.New("audit event dropped")
+
+// Emitter enfileira eventos e os entrega ao Sink em lotes, em segundo plano.
+// A entrega é best-effort: a fila fica em memória, Emit descarta o evento
+// quando ela está cheia, e lotes que falham são reenviados com backoff
+// exponencial até serem aceitos ou o contexto de Close expirar, quando são
+// descartados. Os métodos aceitam receptor nil, para que a auditoria seja
+// opcional.
+type Emitter struct {
+	sink          Sink
+	queue         chan Event
+	batchSize     int
+	flushInterval time.Duration
+	maxBackoff    time.Duration
+	logger        *slog.Logger
+	metrics       *metrics.Metrics
+
+	dropped atomic.Int64
+
+	// mu protege closed: Emit enfileira sob leitura, e Close só libera a
+	// entrega final (drain) depois que nenhum Emit pode mais enfileirar
+	mu     sync.RWMutex
+	closed bool
+	drain  chan struct{}
+	done   chan struct{}
+	once   sync.Once
+
+	// ctx é cancelado quando o contexto de Close expira, interrompendo os
+	// reenvios pendentes
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// EmitterOption configura o Emitter
+type EmitterOption func(*Emitter)
+
+// WithQueueSize define quantos eventos aguardam entrega (padrão: 1000)
+func WithQueueSize(size int) EmitterOption {
+	return func(e *Emitter) {
+		e.queue = make(chan Event, size)
+	}
+}
+
+// WithBatchSize define o tamanho máximo dos lotes enviados ao Sink (padrão: 100)
+func WithBatchSize(size int) EmitterOption {
+	return func(e *Emitter) {
+		e.batchSize = size
+	}
+}
+
+// WithFlushInterval define o tempo máximo que um lote incompleto aguarda
+// antes de ser enviado (padrão: 1s)
+func WithFlushInterval(interval time.Duration) EmitterOption {
+	return func(e *Emitter) {
+		e.flushInterval = interval
+	}
+}
+
+// WithMaxBackoff define o intervalo máximo entre reenvios de um lote (padrão: 30s)
+func WithMaxBackoff(backoff time.Duration) EmitterOption {
+	return func(e *Emitter) {
+		e.maxBackoff = backoff
+	}
+}
+
+// WithLogger registra falhas de entrega e eventos descartados
+func WithLogger(logger *slog.Logger) EmitterOption {
+	return func(e *Emitter) {
+		e.logger = logger
+	}
+}
+
+// WithMetrics conta os eventos descartados em rate_limiter_audit_events_dropped_total
+func WithMetrics(m *metrics.Metrics) EmitterOption {
+	return func(e *Emitter) {
+		e.metrics = m
+	}
+}
+
+// NewEmitter cria o Emitter e inicia a entrega em segundo plano
+func NewEmitter(sink Sink, opts ...EmitterOption) *Emitter {
+	e := &Emitter{
+		sink:          sink,
+		queue:         make(chan Event, 1000),
+		batchSize:     100,
+		flushInterval: time.Second,
+		maxBackoff:    30 * time.Second,
+		logger:        slog.New(slog.DiscardHandler),
+		drain:         make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	e.ctx, e.cancel = context.WithCancel(context.Background())
+
+	go e.run()
+	return e
+}
+
+// Emit enfileira o evento, preenchendo ID e horário se vazios. Nunca bloqueia
+// a requisição: com a fila cheia (ex: Sink lento ou indisponível) ou o
+// Emitter fechado, descarta o evento e retorna ErrDropped.
+func (e *Emitter) Emit(ctx context.Context, event Event) error {
+	if e == nil {
+		return nil
+	}
+	if event.ID == "" {
+		event.ID = newEventID()
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closed {
+		return e.drop(event)
+	}
+
+	select {
+	case e.queue <- event:
+		return nil
+	default:
+		return e.drop(event)
+	}
+}
+
+// Dropped retorna quantos eventos foram descartados
+func (e *Emitter) Dropped() int64 {
+	if e == nil {
+		return 0
+	}
+	return e.dropped.Load()
+}
+
+// Close interrompe o recebimento de eventos, entrega os que estão na fila e
+// fecha o Sink. Se ctx expirar antes, os reenvios são interrompidos e os
+// eventos pendentes contados como descartados.
+func (e *Emitter) Close(ctx context.Context) error {
+	if e == nil {
+		return nil
+	}
+	e.once.Do(func() {
+		// Depois do Lock nenhum Emit enfileira mais, e a fila pode ser esvaziada
+		e.mu.Lock()
+		e.closed = true
+		e.mu.Unlock()
+		close(e.drain)
+	})
+
+	select {
+	case <-e.done:
+		e.cancel()
+		return e.sink.Close()
+	case <-ctx.Done():
+		e.cancel()
+		return ctx.Err()
+	}
+}
+
+func (e *Emitter) drop(event Event) error {
+	e.dropped.Add(1)
+	e.metrics.ObserveAuditDrops(1)
+	e.logger.Error("audit event dropped",
+		slog.String("id", event.ID),
+		slog.String("type", string(event.Type)),
+		slog.String("identifier", event.Identifier),
+	)
+	return ErrDropped
+}
+
+// run agrupa os eventos em lotes e os entrega até o Emitter ser fechado
+func (e *Emitter) run() {
+	defer close(e.done)
+
+	ticker := time.NewTicker(e.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]Event, 0, e.batchSize)
+	for {
+		select {
+		case event := <-e.queue:
+			batch = append(batch, event)
+			if len(batch) < e.batchSize {
+				continue
+			}
+		case <-ticker.C:
+		case <-e.drain:
+			// Entrega o que restou na fila antes de encerrar
+			for {
+				select {
+				case event := <-e.queue:
+					batch = append(batch, event)
+					if len(batch) >= e.batchSize {
+						e.deliver(batch)
+						batch = batch[:0]
+					}
+				default:
+					e.deliver(batch)
+					return
+				}
+			}
+		}
+
+		e.deliver(batch)
+		batch = batch[:0]
+	}
+}
+
+// deliver envia o lote ao Sink, repetindo com backoff exponencial até ser
+// aceito. Se o contexto de Close expirar, desiste e descarta o lote.
+func (e *Emitter) deliver(batch []Event) {
+	if len(batch) == 0 {
+		return
+	}
+
+	backoff := 100 * time.Millisecond
+	for {
+		if e.ctx.Err() != nil {
+			e.dropBatch(batch)
+			return
+		}
+
+		err := e.sink.Write(e.ctx, batch)
+		if err == nil {
+			return
+		}
+
+		e.logger.Warn("audit delivery failed, retrying",
+			slog.Int("events", len(batch)),
+			slog.Duration("backoff", backoff),
+			slog.String("error", err.Error()),
+		)
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-e.ctx.Done():
+			timer.Stop()
+		}
+		backoff = min(backoff*2, e.maxBackoff)
+	}
+}
+
+// dropBatch descarta um lote que não pôde ser entregue
+func (e *Emitter) dropBatch(batch []Event) {
+	e.dropped.Add(int64(len(batch)))
+	e.metrics.ObserveAuditDrops(len(batch))
+	e.logger.Error("audit events dropped",
+		slog.Int("events", len(batch)),
+	)
+}
@@ -0,0 +1,165 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// WriterSink escreve os eventos como JSON lines (ex: os.Stdout)
+type WriterSink struct {
+	mu     sync.Mutex
+	writer io.Writer
+}
+
+// NewWriterSink cria um Sink que escreve uma linha JSON por evento
+func NewWriterSink(writer io.Writer) *WriterSink {
+	return &WriterSink{writer: writer}
+}
+
+func (s *WriterSink) Write(ctx context.Context, events []Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return writeLines(s.writer, events)
+}
+
+func (s *WriterSink) Close() error {
+	return nil
+}
+
+// FileSink acrescenta os eventos como JSON lines a um arquivo, sincronizando
+// o arquivo em disco a cada lote
+type FileSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileSink abre (ou cria) o arquivo em modo append
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o640)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit file: %w", err)
+	}
+	return &FileSink{file: file}, nil
+}
+
+func (s *FileSink) Write(ctx context.Context, events []Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := writeLines(s.file, events); err != nil {
+		return err
+	}
+	return s.file.Sync()
+}
+
+func (s *FileSink) Close() error {
+	return s.file.Close()
+}
+
+// writeLines escreve o lote de uma vez, para não intercalar linhas parciais
+func writeLines(writer io.Writer, events []Event) error {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	for _, event := range events {
+		if err := encoder.Encode(event); err != nil {
+			return err
+		}
+	}
+	_, err := writer.Write(buffer.Bytes())
+	return err
+}
+
+// RedisStreamSink adiciona os eventos a um Redis Stream (XADD), com o evento
+// serializado no campo "event"
+type RedisStreamSink struct {
+	client *redis.Client
+	stream string
+	maxLen int64
+}
+
+// NewRedisStreamSink cria o Sink no stream informado. maxLen limita o tamanho
+// aproximado do stream (0 não limita).
+func NewRedisStreamSink(client *redis.Client, stream string, maxLen int64) *RedisStreamSink {
+	return &RedisStreamSink{
+		client: client,
+		stream: stream,
+		maxLen: maxLen,
+	}
+}
+
+func (s *RedisStreamSink) Write(ctx context.Context, events []Event) error {
+	pipe := s.client.Pipeline()
+	for _, event := range events {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		pipe.XAdd(ctx, &redis.XAddArgs{
+			Stream:       s.stream,
+			MaxLenApprox: s.maxLen,
+			Values:       map[string]interface{}{"id": event.ID, "event": data},
+		})
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// Close não fecha o cliente, que pertence ao storage
+func (s *RedisStreamSink) Close() error {
+	return nil
+}
+
+// WebhookSink envia cada lote como um array JSON em um POST para a URL
+// configurada. Respostas fora da faixa 2xx são tratadas como falha e o lote é
+// reenviado.
+type WebhookSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookSink cria o Sink para a URL informada
+func NewWebhookSink(url string, timeout time.Duration) *WebhookSink {
+	return &WebhookSink{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (s *WebhookSink) Write(ctx context.Context, events []Event) error {
+	body, err := json.Marshal(events)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("audit webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (s *WebhookSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,154 @@
+// Package heavyhitters identifica os maiores consumidores (IPs e tokens) de
+// cada tipo de limite, por minuto, para relatórios dos últimos N minutos.
+package heavyhitters
+
+import (
+	"container/heap"
+	"context"
+	"sort"
+	"time"
+)
+
+// DefaultCapacity é o número máximo de identificadores acompanhados por tipo
+// de limite em cada minuto
+const DefaultCapacity = 1000
+
+// Consumer é o consumo de um identificador em uma janela de tempo
+type Consumer struct {
+	// Identifier é o identificador como aparece nas chaves do storage (IPs
+	// agregados, tokens com hash)
+	Identifier string
+	Requests   int64
+	// Denied é o número de requisições negadas
+	Denied int64
+	// PeakRate é a maior contagem observada em uma janela de 1 segundo
+	PeakRate int64
+}
+
+// Tracker registra as decisões do rate limiter e informa os maiores consumidores
+type Tracker interface {
+	// Record registra uma requisição do identificador. count é a contagem na
+	// janela atual do limite (zero ou negativo se desconhecida). Não deve
+	// bloquear a requisição.
+	Record(limitType, identifier string, count int64, allowed bool)
+
+	// Top retorna os n identificadores com mais requisições nos últimos
+	// minutos da janela informada, em ordem decrescente (n <= 0 retorna todos)
+	Top(ctx context.Context, limitType string, window time.Duration, n int) ([]Consumer, error)
+
+	// Close libera os recursos do tracker, enviando os registros pendentes
+	Close(ctx context.Context) error
+}
+
+// minute retorna o minuto (Unix) de t, usado para agrupar os registros
+func minute(t time.Time) int64 {
+	return t.Unix() / 60
+}
+
+// windowMinutes retorna quantos minutos, incluindo o atual, cobrem a janela
+// (no mínimo 1 e no máximo retention)
+func windowMinutes(window time.Duration, retention int) int {
+	minutes := int((window + time.Minute - 1) / time.Minute)
+	return min(max(minutes, 1), retention)
+}
+
+// sortConsumers ordena por requisições e, em caso de empate, por negações e
+// identificador, e mantém os n primeiros
+func sortConsumers(consumers []Consumer, n int) []Consumer {
+	sort.Slice(consumers, func(i, j int) bool {
+		a, b := consumers[i], consumers[j]
+		if a.Requests != b.Requests {
+			return a.Requests > b.Requests
+		}
+		if a.Denied != b.Denied {
+			return a.Denied > b.Denied
+		}
+		return a.Identifier < b.Identifier
+	})
+	if n > 0 && len(consumers) > n {
+		consumers = consumers[:n]
+	}
+	return consumers
+}
+
+// summary acompanha até capacity identificadores com o algoritmo Space-Saving:
+// com a capacidade esgotada, um novo identificador substitui o de menor
+// contagem e herda essa contagem. A memória fica limitada mesmo com muitos
+// identificadores distintos (ex: IPs de um ataque distribuído), e os
+// consumidores com mais requisições que o menor acompanhado nunca são
+// descartados; suas contagens podem ser superestimadas.
+type summary struct {
+	capacity int
+	entries  map[string]*summaryEntry
+	heap     summaryHeap
+}
+
+type summaryEntry struct {
+	Consumer
+	index int
+}
+
+func newSummary(capacity int) *summary {
+	if capacity <= 0 {
+		capacity = DefaultCapacity
+	}
+	return &summary{
+		capacity: capacity,
+		entries:  make(map[string]*summaryEntry),
+	}
+}
+
+func (s *summary) record(identifier string, count int64, allowed bool) {
+	entry, ok := s.entries[identifier]
+	if !ok {
+		entry = &summaryEntry{Consumer: Consumer{Identifier: identifier}}
+		if len(s.entries) >= s.capacity {
+			evicted := heap.Pop(&s.heap).(*summaryEntry)
+			delete(s.entries, evicted.Identifier)
+			entry.Requests = evicted.Requests
+		}
+		s.entries[identifier] = entry
+		heap.Push(&s.heap, entry)
+	}
+
+	entry.Requests++
+	if !allowed {
+		entry.Denied++
+	}
+	entry.PeakRate = max(entry.PeakRate, count)
+	heap.Fix(&s.heap, entry.index)
+}
+
+func (s *summary) consumers() []Consumer {
+	consumers := make([]Consumer, 0, len(s.entries))
+	for _, entry := range s.entries {
+		consumers = append(consumers, entry.Consumer)
+	}
+	return consumers
+}
+
+// summaryHeap é um min-heap de entradas por número de requisições
+type summaryHeap []*summaryEntry
+
+func (h summaryHeap) Len() int           { return len(h) }
+func (h summaryHeap) Less(i, j int) bool { return h[i].Requests < h[j].Requests }
+
+func (h summaryHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *summaryHeap) Push(x any) {
+	entry := x.(*summaryEntry)
+	entry.index = len(*h)
+	*h = append(*h, entry)
+}
+
+func (h *summaryHeap) Pop() any {
+	old := *h
+	entry := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return entry
+}
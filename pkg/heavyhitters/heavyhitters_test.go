@@ -0,0 +1,223 @@
+package heavyhitters
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+)
+
+func TestMemoryTracker_Top(t *testing.T) {
+	tracker := NewMemoryTracker(60)
+	now := time.Date(2026, 10, 18, 12, 0, 30, 0, time.UTC)
+	tracker.now = func() time.Time { return now }
+
+	// Minuto anterior
+	now = now.Add(-time.Minute)
+	for i := 0; i < 5; i++ {
+		tracker.Record("ip", "10.0.0.1", int64(i+1), true)
+	}
+	now = now.Add(time.Minute)
+
+	for i := 0; i < 3; i++ {
+		tracker.Record("ip", "10.0.0.1", int64(i+1), true)
+	}
+	for i := 0; i < 6; i++ {
+		tracker.Record("ip", "10.0.0.2", int64(i+1), i < 4)
+	}
+	tracker.Record("ip", "10.0.0.3", 1, true)
+	tracker.Record("token", "api-key", 1, true)
+
+	ctx := context.Background()
+	consumers, err := tracker.Top(ctx, "ip", 5*time.Minute, 2)
+	if err != nil {
+		t.Fatalf("Top() error = %v", err)
+	}
+	expected := []Consumer{
+		{Identifier: "10.0.0.1", Requests: 8, Denied: 0, PeakRate: 5},
+		{Identifier: "10.0.0.2", Requests: 6, Denied: 2, PeakRate: 6},
+	}
+	if fmt.Sprint(consumers) != fmt.Sprint(expected) {
+		t.Errorf("Top() = %+v, expected %+v", consumers, expected)
+	}
+
+	consumers, _ = tracker.Top(ctx, "ip", time.Minute, 0)
+	if len(consumers) != 3 || consumers[0].Identifier != "10.0.0.2" {
+		t.Errorf("Top() of the current minute = %+v, expected 3 consumers led by 10.0.0.2", consumers)
+	}
+
+	consumers, _ = tracker.Top(ctx, "token", time.Minute, 10)
+	if len(consumers) != 1 || consumers[0].Identifier != "api-key" {
+		t.Errorf("Top() for tokens = %+v, expected only api-key", consumers)
+	}
+}
+
+func TestMemoryTracker_Retention(t *testing.T) {
+	tracker := NewMemoryTracker(2)
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	tracker.now = func() time.Time { return now }
+
+	tracker.Record("ip", "10.0.0.1", 1, true)
+	now = now.Add(2 * time.Minute)
+	tracker.Record("ip", "10.0.0.2", 1, true)
+
+	consumers, _ := tracker.Top(context.Background(), "ip", time.Hour, 10)
+	if len(consumers) != 1 || consumers[0].Identifier != "10.0.0.2" {
+		t.Errorf("Top() = %+v, expected only the minutes within the retention", consumers)
+	}
+	if len(tracker.buckets) != 1 {
+		t.Errorf("got %d buckets, expected expired minutes to be removed", len(tracker.buckets))
+	}
+}
+
+func TestSummary_Capacity(t *testing.T) {
+	// Com capacidade k, identificadores com mais de N/k das N requisições
+	// (aqui, 170/10) são sempre mantidos
+	s := newSummary(10)
+
+	for i := 0; i < 50; i++ {
+		s.record("heavy", 0, true)
+	}
+	for i := 0; i < 20; i++ {
+		s.record("medium", 0, true)
+	}
+	// Muitos identificadores distintos com uma requisição cada
+	for i := 0; i < 100; i++ {
+		s.record(fmt.Sprintf("10.1.%d.%d", i/256, i%256), 0, true)
+	}
+
+	if len(s.entries) != 10 || len(s.heap) != 10 {
+		t.Fatalf("summary has %d entries, expected the capacity of 10", len(s.entries))
+	}
+
+	consumers := sortConsumers(s.consumers(), 2)
+	if consumers[0].Identifier != "heavy" || consumers[0].Requests != 50 {
+		t.Errorf("top consumer = %+v, expected heavy with 50 requests", consumers[0])
+	}
+	if consumers[1].Identifier != "medium" || consumers[1].Requests != 20 {
+		t.Errorf("second consumer = %+v, expected medium with 20 requests", consumers[1])
+	}
+}
+
+func newTestRedisTracker(t *testing.T, now *time.Time) (*RedisTracker, *miniredis.Miniredis) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	// Os envios são feitos pelo teste, com flush e Close
+	tracker := NewRedisTracker(client, 60,
+		WithKeyPrefix("checkout:"),
+		WithFlushInterval(time.Hour),
+		WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+	)
+	tracker.now = func() time.Time { return *now }
+	t.Cleanup(func() { tracker.Close(context.Background()) })
+	return tracker, server
+}
+
+func TestRedisTracker_Top(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 30, 0, time.UTC)
+	tracker, server := newTestRedisTracker(t, &now)
+	ctx := context.Background()
+
+	// Minuto anterior, enviado em um flush separado
+	now = now.Add(-time.Minute)
+	for i := 0; i < 5; i++ {
+		tracker.Record("ip", "10.0.0.1", int64(i+1), true)
+	}
+	if err := tracker.flush(ctx); err != nil {
+		t.Fatalf("flush() error = %v", err)
+	}
+	now = now.Add(time.Minute)
+
+	for i := 0; i < 3; i++ {
+		tracker.Record("ip", "10.0.0.1", int64(i+1), true)
+	}
+	for i := 0; i < 6; i++ {
+		tracker.Record("ip", "10.0.0.2", int64(i+1), i < 4)
+	}
+	tracker.Record("ip", "10.0.0.3", 1, true)
+	tracker.Record("token", "api-key", 1, true)
+	if err := tracker.flush(ctx); err != nil {
+		t.Fatalf("flush() error = %v", err)
+	}
+
+	consumers, err := tracker.Top(ctx, "ip", 5*time.Minute, 2)
+	if err != nil {
+		t.Fatalf("Top() error = %v", err)
+	}
+	expected := []Consumer{
+		{Identifier: "10.0.0.1", Requests: 8, Denied: 0, PeakRate: 5},
+		{Identifier: "10.0.0.2", Requests: 6, Denied: 2, PeakRate: 6},
+	}
+	if fmt.Sprint(consumers) != fmt.Sprint(expected) {
+		t.Errorf("Top() = %+v, expected %+v", consumers, expected)
+	}
+
+	consumers, _ = tracker.Top(ctx, "ip", time.Minute, 0)
+	if len(consumers) != 3 || consumers[0].Identifier != "10.0.0.2" {
+		t.Errorf("Top() of the current minute = %+v, expected 3 consumers led by 10.0.0.2", consumers)
+	}
+
+	consumers, _ = tracker.Top(ctx, "token", time.Minute, 10)
+	if len(consumers) != 1 || consumers[0].Identifier != "api-key" {
+		t.Errorf("Top() for tokens = %+v, expected only api-key", consumers)
+	}
+
+	// Os minutos expiram após a retenção, e as chaves temporárias do Top são removidas
+	key := fmt.Sprintf("checkout:heavy_hitters:ip:requests:%d", minute(now))
+	if ttl := server.TTL(key); ttl != 61*time.Minute {
+		t.Errorf("TTL(%s) = %v, expected %v", key, ttl, 61*time.Minute)
+	}
+	for _, key := range server.Keys() {
+		if strings.Contains(key, ":top:") {
+			t.Errorf("temporary key %s was not removed", key)
+		}
+	}
+}
+
+func TestRedisTracker_Close(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	tracker, _ := newTestRedisTracker(t, &now)
+
+	tracker.Record("ip", "10.0.0.1", 1, true)
+	ctx := context.Background()
+	if err := tracker.Close(ctx); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	consumers, err := tracker.Top(ctx, "ip", time.Minute, 10)
+	if err != nil {
+		t.Fatalf("Top() error = %v", err)
+	}
+	if len(consumers) != 1 || consumers[0].Identifier != "10.0.0.1" {
+		t.Errorf("Top() = %+v, expected the records sent by Close()", consumers)
+	}
+}
+
+func TestRedisTracker_DroppedFlush(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	tracker, server := newTestRedisTracker(t, &now)
+
+	tracker.Record("ip", "10.0.0.1", 1, true)
+	tracker.Record("ip", "10.0.0.1", 2, false)
+	tracker.Record("token", "api-key", 1, true)
+
+	server.Close()
+	if err := tracker.flush(context.Background()); err == nil {
+		t.Fatal("flush() should return an error when Redis is unavailable")
+	}
+	if dropped := tracker.Dropped(); dropped != 3 {
+		t.Errorf("Dropped() = %d, expected 3", dropped)
+	}
+	if len(tracker.pending) != 0 {
+		t.Errorf("got %d pending buckets, expected the failed records to be discarded", len(tracker.pending))
+	}
+}
@@ -0,0 +1,104 @@
+package heavyhitters
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryTracker acompanha os maiores consumidores no próprio processo, sem
+// compartilhar os dados entre instâncias (ver RedisTracker)
+type MemoryTracker struct {
+	mu        sync.Mutex
+	capacity  int
+	retention int
+	buckets   map[bucketKey]*summary
+	now       func() time.Time
+}
+
+type bucketKey struct {
+	limitType string
+	minute    int64
+}
+
+// MemoryOption configura o MemoryTracker
+type MemoryOption func(*MemoryTracker)
+
+// WithMemoryCapacity define quantos identificadores são acompanhados por tipo
+// de limite em cada minuto (padrão: DefaultCapacity)
+func WithMemoryCapacity(capacity int) MemoryOption {
+	return func(t *MemoryTracker) {
+		t.capacity = capacity
+	}
+}
+
+// NewMemoryTracker cria o tracker mantendo os últimos retentionMinutes minutos
+func NewMemoryTracker(retentionMinutes int, opts ...MemoryOption) *MemoryTracker {
+	t := &MemoryTracker{
+		capacity:  DefaultCapacity,
+		retention: max(retentionMinutes, 1),
+		buckets:   make(map[bucketKey]*summary),
+		now:       time.Now,
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+func (t *MemoryTracker) Record(limitType, identifier string, count int64, allowed bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := bucketKey{limitType: limitType, minute: minute(t.now())}
+	bucket, ok := t.buckets[key]
+	if !ok {
+		t.expire(key.minute)
+		bucket = newSummary(t.capacity)
+		t.buckets[key] = bucket
+	}
+	bucket.record(identifier, count, allowed)
+}
+
+// expire remove os minutos fora da retenção. Deve ser chamado com o mutex travado.
+func (t *MemoryTracker) expire(current int64) {
+	for key := range t.buckets {
+		if key.minute <= current-int64(t.retention) {
+			delete(t.buckets, key)
+		}
+	}
+}
+
+func (t *MemoryTracker) Top(ctx context.Context, limitType string, window time.Duration, n int) ([]Consumer, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	current := minute(t.now())
+	totals := make(map[string]*Consumer)
+	for i := 0; i < windowMinutes(window, t.retention); i++ {
+		bucket, ok := t.buckets[bucketKey{limitType: limitType, minute: current - int64(i)}]
+		if !ok {
+			continue
+		}
+		for _, entry := range bucket.entries {
+			total, ok := totals[entry.Identifier]
+			if !ok {
+				total = &Consumer{Identifier: entry.Identifier}
+				totals[entry.Identifier] = total
+			}
+			total.Requests += entry.Requests
+			total.Denied += entry.Denied
+			total.PeakRate = max(total.PeakRate, entry.PeakRate)
+		}
+	}
+
+	consumers := make([]Consumer, 0, len(totals))
+	for _, total := range totals {
+		consumers = append(consumers, *total)
+	}
+	return sortConsumers(consumers, n), nil
+}
+
+func (t *MemoryTracker) Close(ctx context.Context) error {
+	return nil
+}
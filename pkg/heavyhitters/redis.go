@@ -0,0 +1,273 @@
+package heavyhitters
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Métricas mantidas em um sorted set por tipo de limite e minuto
+const (
+	requestsMetric = "requests"
+	deniedMetric   = "denied"
+	peakMetric     = "peak"
+)
+
+// RedisTracker agrega os registros em memória e os envia periodicamente a
+// sorted sets do Redis, um por tipo de limite, métrica e minuto
+// (<prefixo>heavy_hitters:<tipo>:<métrica>:<minuto>), compartilhando o
+// relatório entre as instâncias. Registros ainda não enviados são perdidos se
+// o processo terminar sem Close, e os de um envio que falhou são contados em
+// Dropped.
+type RedisTracker struct {
+	client        *redis.Client
+	prefix        string
+	retention     int
+	capacity      int
+	flushInterval time.Duration
+	logger        *slog.Logger
+	now           func() time.Time
+
+	mu      sync.Mutex
+	pending map[bucketKey]*summary
+	dropped atomic.Int64
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// RedisOption configura o RedisTracker
+type RedisOption func(*RedisTracker)
+
+// WithKeyPrefix prefixa as chaves do tracker (ex: "checkout:" para o
+// namespace das chaves do rate limiter)
+func WithKeyPrefix(prefix string) RedisOption {
+	return func(t *RedisTracker) {
+		t.prefix = prefix
+	}
+}
+
+// WithRedisCapacity define quantos identificadores são agregados por tipo de
+// limite entre dois envios (padrão: DefaultCapacity)
+func WithRedisCapacity(capacity int) RedisOption {
+	return func(t *RedisTracker) {
+		t.capacity = capacity
+	}
+}
+
+// WithFlushInterval define o intervalo de envio dos registros (padrão: 1s)
+func WithFlushInterval(interval time.Duration) RedisOption {
+	return func(t *RedisTracker) {
+		t.flushInterval = interval
+	}
+}
+
+// WithLogger registra falhas de envio ao Redis
+func WithLogger(logger *slog.Logger) RedisOption {
+	return func(t *RedisTracker) {
+		t.logger = logger
+	}
+}
+
+// NewRedisTracker cria o tracker mantendo os últimos retentionMinutes minutos
+// no Redis e inicia o envio periódico, encerrado por Close
+func NewRedisTracker(client *redis.Client, retentionMinutes int, opts ...RedisOption) *RedisTracker {
+	t := &RedisTracker{
+		client:        client,
+		retention:     max(retentionMinutes, 1),
+		capacity:      DefaultCapacity,
+		flushInterval: time.Second,
+		logger:        slog.Default(),
+		now:           time.Now,
+		pending:       make(map[bucketKey]*summary),
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	go t.run()
+	return t
+}
+
+func (t *RedisTracker) key(limitType, metric string, minute int64) string {
+	return fmt.Sprintf("%sheavy_hitters:%s:%s:%d", t.prefix, limitType, metric, minute)
+}
+
+func (t *RedisTracker) Record(limitType, identifier string, count int64, allowed bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := bucketKey{limitType: limitType, minute: minute(t.now())}
+	bucket, ok := t.pending[key]
+	if !ok {
+		bucket = newSummary(t.capacity)
+		t.pending[key] = bucket
+	}
+	bucket.record(identifier, count, allowed)
+}
+
+func (t *RedisTracker) run() {
+	defer close(t.done)
+
+	ticker := time.NewTicker(t.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-t.stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			t.flush(ctx)
+			cancel()
+		}
+	}
+}
+
+// flush envia os registros pendentes em um único pipeline. Em caso de falha,
+// os registros do intervalo são descartados, contados em Dropped e logados.
+func (t *RedisTracker) flush(ctx context.Context) error {
+	t.mu.Lock()
+	pending := t.pending
+	t.pending = make(map[bucketKey]*summary)
+	t.mu.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	// Os minutos expiram depois da retenção, com margem para o minuto atual
+	expiration := time.Duration(t.retention+1) * time.Minute
+
+	pipe := t.client.Pipeline()
+	for key, bucket := range pending {
+		requestsKey := t.key(key.limitType, requestsMetric, key.minute)
+		deniedKey := t.key(key.limitType, deniedMetric, key.minute)
+		peakKey := t.key(key.limitType, peakMetric, key.minute)
+
+		var peaks []redis.Z
+		for _, consumer := range bucket.consumers() {
+			pipe.ZIncrBy(ctx, requestsKey, float64(consumer.Requests), consumer.Identifier)
+			if consumer.Denied > 0 {
+				pipe.ZIncrBy(ctx, deniedKey, float64(consumer.Denied), consumer.Identifier)
+			}
+			if consumer.PeakRate > 0 {
+				peaks = append(peaks, redis.Z{Score: float64(consumer.PeakRate), Member: consumer.Identifier})
+			}
+		}
+		if len(peaks) > 0 {
+			pipe.ZAddArgs(ctx, peakKey, redis.ZAddArgs{GT: true, Members: peaks})
+		}
+		for _, k := range []string{requestsKey, deniedKey, peakKey} {
+			pipe.Expire(ctx, k, expiration)
+		}
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		var requests int64
+		for _, bucket := range pending {
+			for _, consumer := range bucket.consumers() {
+				requests += consumer.Requests
+			}
+		}
+		t.dropped.Add(requests)
+		t.logger.Error("heavy hitters flush failed, records dropped",
+			slog.Int64("requests", requests),
+			slog.String("error", err.Error()),
+		)
+		return err
+	}
+	return nil
+}
+
+// Dropped retorna quantas requisições registradas foram descartadas por
+// falhas de envio ao Redis
+func (t *RedisTracker) Dropped() int64 {
+	return t.dropped.Load()
+}
+
+// Top soma os minutos da janela com ZUNIONSTORE em chaves temporárias, sem
+// transferir os sorted sets completos. Registros ainda não enviados (até um
+// intervalo de envio) não são incluídos.
+func (t *RedisTracker) Top(ctx context.Context, limitType string, window time.Duration, n int) ([]Consumer, error) {
+	current := minute(t.now())
+	minutes := windowMinutes(window, t.retention)
+	keys := make(map[string][]string, 3)
+	for i := 0; i < minutes; i++ {
+		for _, metric := range []string{requestsMetric, deniedMetric, peakMetric} {
+			keys[metric] = append(keys[metric], t.key(limitType, metric, current-int64(i)))
+		}
+	}
+
+	suffix := make([]byte, 8)
+	rand.Read(suffix)
+	temp := make(map[string]string, 3)
+	for _, metric := range []string{requestsMetric, deniedMetric, peakMetric} {
+		temp[metric] = fmt.Sprintf("%sheavy_hitters:%s:%s:top:%s", t.prefix, limitType, metric, hex.EncodeToString(suffix))
+	}
+	defer t.client.Del(context.WithoutCancel(ctx), temp[requestsMetric], temp[deniedMetric], temp[peakMetric])
+
+	pipe := t.client.Pipeline()
+	pipe.ZUnionStore(ctx, temp[requestsMetric], &redis.ZStore{Keys: keys[requestsMetric], Aggregate: "SUM"})
+	pipe.ZUnionStore(ctx, temp[deniedMetric], &redis.ZStore{Keys: keys[deniedMetric], Aggregate: "SUM"})
+	pipe.ZUnionStore(ctx, temp[peakMetric], &redis.ZStore{Keys: keys[peakMetric], Aggregate: "MAX"})
+	stop := int64(n - 1)
+	if n <= 0 {
+		stop = -1
+	}
+	top := pipe.ZRevRangeWithScores(ctx, temp[requestsMetric], 0, stop)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("error reading heavy hitters: %w", err)
+	}
+
+	ranked := top.Val()
+	if len(ranked) == 0 {
+		return []Consumer{}, nil
+	}
+
+	identifiers := make([]string, len(ranked))
+	for i, z := range ranked {
+		identifiers[i] = z.Member.(string)
+	}
+
+	pipe = t.client.Pipeline()
+	denied := pipe.ZMScore(ctx, temp[deniedMetric], identifiers...)
+	peaks := pipe.ZMScore(ctx, temp[peakMetric], identifiers...)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("error reading heavy hitters: %w", err)
+	}
+
+	consumers := make([]Consumer, len(ranked))
+	for i, z := range ranked {
+		consumers[i] = Consumer{
+			Identifier: identifiers[i],
+			Requests:   int64(z.Score),
+			Denied:     int64(denied.Val()[i]),
+			PeakRate:   int64(peaks.Val()[i]),
+		}
+	}
+	return sortConsumers(consumers, n), nil
+}
+
+// Close interrompe o envio periódico e envia os registros pendentes
+func (t *RedisTracker) Close(ctx context.Context) error {
+	t.once.Do(func() {
+		close(t.stop)
+	})
+
+	select {
+	case <-t.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return t.flush(ctx)
+}
@@ -0,0 +1,148 @@
+// Package jwtauth verifica JWTs (HMAC ou chaves de um JWKS) e identifica o
+// cliente pelas claims, para uso com limiter.WithTokenVerifier.
+package jwtauth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Config define como os JWTs são verificados e quais claims identificam o cliente
+type Config struct {
+	// HMACSecret habilita tokens assinados com HS256/HS384/HS512
+	HMACSecret []byte
+	// PublicKeys habilita tokens RS*, PS* e ES*, indexadas pelo kid (ver LoadJWKSFile)
+	PublicKeys map[string]crypto.PublicKey
+	// IdentityClaim é a claim usada como identificador do limite (padrão: sub)
+	IdentityClaim string
+	// PlanClaim é a claim que seleciona o tier de limite (vazio desabilita)
+	PlanClaim string
+	// Issuer e Audience, se definidos, são exigidos nas claims iss e aud
+	Issuer   string
+	Audience string
+	// Leeway é a tolerância a diferenças de relógio na verificação de exp,
+	// nbf e iat. A claim exp é sempre exigida.
+	Leeway time.Duration
+}
+
+// Verifier verifica a assinatura de JWTs e extrai a identidade do cliente.
+// Implementa limiter.TokenVerifier.
+type Verifier struct {
+	config Config
+	parser *jwt.Parser
+}
+
+func NewVerifier(config Config) (*Verifier, error) {
+	var methods []string
+	if len(config.HMACSecret) > 0 {
+		methods = append(methods, "HS256", "HS384", "HS512")
+	}
+	if len(config.PublicKeys) > 0 {
+		methods = append(methods, "RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512")
+	}
+	if len(methods) == 0 {
+		return nil, fmt.Errorf("JWT verifier requires an HMAC secret or public keys")
+	}
+
+	if config.IdentityClaim == "" {
+		config.IdentityClaim = "sub"
+	}
+
+	// Tokens sem exp seriam aceitos para sempre, inclusive depois de vazados
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods(methods),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(config.Leeway),
+	}
+	if config.Issuer != "" {
+		options = append(options, jwt.WithIssuer(config.Issuer))
+	}
+	if config.Audience != "" {
+		options = append(options, jwt.WithAudience(config.Audience))
+	}
+
+	return &Verifier{
+		config: config,
+		parser: jwt.NewParser(options...),
+	}, nil
+}
+
+// Verify valida o JWT e retorna o identificador no formato <claim>:<valor>
+// (ex: sub:1234) e o tier indicado pela claim de plano
+func (v *Verifier) Verify(ctx context.Context, token string) (string, string, error) {
+	claims := jwt.MapClaims{}
+	if _, err := v.parser.ParseWithClaims(token, claims, v.key); err != nil {
+		return "", "", fmt.Errorf("invalid JWT: %w", err)
+	}
+
+	identity, ok := claimString(claims, v.config.IdentityClaim)
+	if !ok {
+		return "", "", fmt.Errorf("invalid JWT: missing %s claim", v.config.IdentityClaim)
+	}
+
+	var tier string
+	if v.config.PlanClaim != "" {
+		tier, _ = claimString(claims, v.config.PlanClaim)
+	}
+
+	return v.config.IdentityClaim + ":" + identity, tier, nil
+}
+
+// key seleciona a chave de verificação de acordo com o algoritmo do token,
+// impedindo que uma chave seja usada com um algoritmo de outro tipo
+func (v *Verifier) key(token *jwt.Token) (interface{}, error) {
+	switch token.Method.(type) {
+	case *jwt.SigningMethodHMAC:
+		return v.config.HMACSecret, nil
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+		key, err := v.publicKey(token)
+		if err != nil {
+			return nil, err
+		}
+		if rsaKey, ok := key.(*rsa.PublicKey); ok {
+			return rsaKey, nil
+		}
+	case *jwt.SigningMethodECDSA:
+		key, err := v.publicKey(token)
+		if err != nil {
+			return nil, err
+		}
+		if ecdsaKey, ok := key.(*ecdsa.PublicKey); ok {
+			return ecdsaKey, nil
+		}
+	}
+	return nil, fmt.Errorf("no key for algorithm %v", token.Header["alg"])
+}
+
+func (v *Verifier) publicKey(token *jwt.Token) (crypto.PublicKey, error) {
+	kid, _ := token.Header["kid"].(string)
+	if key, ok := v.config.PublicKeys[kid]; ok {
+		return key, nil
+	}
+
+	// Sem kid, aceita apenas quando há uma única chave configurada
+	if kid == "" && len(v.config.PublicKeys) == 1 {
+		for _, key := range v.config.PublicKeys {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown key id %q", kid)
+}
+
+func claimString(claims jwt.MapClaims, name string) (string, bool) {
+	switch value := claims[name].(type) {
+	case string:
+		return value, value != ""
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), true
+	default:
+		return "", false
+	}
+}
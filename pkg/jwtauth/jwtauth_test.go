@@ -0,0 +1,243 @@
+package jwtauth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func signToken(t *testing.T, method jwt.SigningMethod, key interface{}, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return signed
+}
+
+func encode(value *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(value.Bytes())
+}
+
+func writeJWKS(t *testing.T, rsaKey *rsa.PrivateKey, ecKey *ecdsa.PrivateKey) string {
+	t.Helper()
+
+	set := map[string]interface{}{
+		"keys": []map[string]string{
+			{"kty": "RSA", "kid": "rsa-1", "use": "sig", "n": encode(rsaKey.N), "e": encode(big.NewInt(int64(rsaKey.E)))},
+			{"kty": "EC", "kid": "ec-1", "crv": "P-256", "x": encode(ecKey.X), "y": encode(ecKey.Y)},
+			{"kty": "oct", "kid": "ignored", "k": "c2VjcmV0"},
+		},
+	}
+	data, err := json.Marshal(set)
+	if err != nil {
+		t.Fatalf("failed to encode JWKS: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("failed to write JWKS: %v", err)
+	}
+	return path
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate ECDSA key: %v", err)
+	}
+	otherRSAKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %v", err)
+	}
+
+	publicKeys, err := LoadJWKSFile(writeJWKS(t, rsaKey, ecKey))
+	if err != nil {
+		t.Fatalf("LoadJWKSFile() error = %v", err)
+	}
+	if len(publicKeys) != 2 {
+		t.Fatalf("LoadJWKSFile() loaded %d keys, expected 2", len(publicKeys))
+	}
+
+	secret := []byte("hmac-secret")
+	verifier, err := NewVerifier(Config{
+		HMACSecret: secret,
+		PublicKeys: publicKeys,
+		PlanClaim:  "plan",
+		Issuer:     "https://auth.example.com",
+		Leeway:     30 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("NewVerifier() error = %v", err)
+	}
+
+	claims := func(extra jwt.MapClaims) jwt.MapClaims {
+		base := jwt.MapClaims{
+			"sub":  "user-42",
+			"plan": "gold",
+			"iss":  "https://auth.example.com",
+			"exp":  time.Now().Add(time.Hour).Unix(),
+		}
+		for key, value := range extra {
+			base[key] = value
+		}
+		return base
+	}
+
+	tests := []struct {
+		name               string
+		token              string
+		expectedIdentifier string
+		expectedTier       string
+		expectError        bool
+	}{
+		{
+			name:               "HMAC signed token",
+			token:              signToken(t, jwt.SigningMethodHS256, secret, "", claims(nil)),
+			expectedIdentifier: "sub:user-42",
+			expectedTier:       "gold",
+		},
+		{
+			name:               "RSA signed token from JWKS",
+			token:              signToken(t, jwt.SigningMethodRS256, rsaKey, "rsa-1", claims(nil)),
+			expectedIdentifier: "sub:user-42",
+			expectedTier:       "gold",
+		},
+		{
+			name:               "ECDSA signed token from JWKS",
+			token:              signToken(t, jwt.SigningMethodES256, ecKey, "ec-1", claims(jwt.MapClaims{"plan": "free"})),
+			expectedIdentifier: "sub:user-42",
+			expectedTier:       "free",
+		},
+		{
+			name:               "Token without plan claim",
+			token:              signToken(t, jwt.SigningMethodHS256, secret, "", claims(jwt.MapClaims{"plan": nil})),
+			expectedIdentifier: "sub:user-42",
+			expectedTier:       "",
+		},
+		{
+			name:        "Wrong HMAC secret",
+			token:       signToken(t, jwt.SigningMethodHS256, []byte("other"), "", claims(nil)),
+			expectError: true,
+		},
+		{
+			name:        "RSA key not in JWKS",
+			token:       signToken(t, jwt.SigningMethodRS256, otherRSAKey, "rsa-1", claims(nil)),
+			expectError: true,
+		},
+		{
+			name:        "Unknown key id",
+			token:       signToken(t, jwt.SigningMethodRS256, rsaKey, "rsa-2", claims(nil)),
+			expectError: true,
+		},
+		{
+			name:        "Key type does not match algorithm",
+			token:       signToken(t, jwt.SigningMethodES256, ecKey, "rsa-1", claims(nil)),
+			expectError: true,
+		},
+		{
+			name:        "Expired token",
+			token:       signToken(t, jwt.SigningMethodHS256, secret, "", claims(jwt.MapClaims{"exp": time.Now().Add(-time.Minute).Unix()})),
+			expectError: true,
+		},
+		{
+			name:               "Expired token within leeway",
+			token:              signToken(t, jwt.SigningMethodHS256, secret, "", claims(jwt.MapClaims{"exp": time.Now().Add(-10 * time.Second).Unix()})),
+			expectedIdentifier: "sub:user-42",
+			expectedTier:       "gold",
+		},
+		{
+			name:        "Token without expiration",
+			token:       signToken(t, jwt.SigningMethodHS256, secret, "", claims(jwt.MapClaims{"exp": nil})),
+			expectError: true,
+		},
+		{
+			name:        "Wrong issuer",
+			token:       signToken(t, jwt.SigningMethodHS256, secret, "", claims(jwt.MapClaims{"iss": "https://evil.example.com"})),
+			expectError: true,
+		},
+		{
+			name:        "Missing subject",
+			token:       signToken(t, jwt.SigningMethodHS256, secret, "", claims(jwt.MapClaims{"sub": nil})),
+			expectError: true,
+		},
+		{
+			name:        "Unsigned token",
+			token:       signToken(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, "", claims(nil)),
+			expectError: true,
+		},
+		{
+			name:        "Not a JWT",
+			token:       "plain-api-key",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identifier, tier, err := verifier.Verify(context.Background(), tt.token)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Verify() expected error, got identifier %q", identifier)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if identifier != tt.expectedIdentifier {
+				t.Errorf("Verify() identifier = %q, expected %q", identifier, tt.expectedIdentifier)
+			}
+			if tier != tt.expectedTier {
+				t.Errorf("Verify() tier = %q, expected %q", tier, tt.expectedTier)
+			}
+		})
+	}
+}
+
+func TestVerifier_IdentityClaim(t *testing.T) {
+	secret := []byte("hmac-secret")
+	verifier, err := NewVerifier(Config{HMACSecret: secret, IdentityClaim: "tenant_id"})
+	if err != nil {
+		t.Fatalf("NewVerifier() error = %v", err)
+	}
+
+	token := signToken(t, jwt.SigningMethodHS256, secret, "", jwt.MapClaims{"sub": "user-42", "tenant_id": "acme", "exp": time.Now().Add(time.Hour).Unix()})
+	identifier, _, err := verifier.Verify(context.Background(), token)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if identifier != "tenant_id:acme" {
+		t.Errorf("Verify() identifier = %q, expected %q", identifier, "tenant_id:acme")
+	}
+}
+
+func TestNewVerifier_RequiresKeys(t *testing.T) {
+	if _, err := NewVerifier(Config{}); err == nil {
+		t.Errorf("NewVerifier() without keys should return an error")
+	}
+	if _, err := NewVerifier(Config{PublicKeys: map[string]crypto.PublicKey{}}); err == nil {
+		t.Errorf("NewVerifier() with empty key set should return an error")
+	}
+}
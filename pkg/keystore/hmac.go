@@ -0,0 +1,45 @@
+package keystore
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+)
+
+// HMACStore valida tokens assinados no formato <id>.<assinatura>, em que a
+// assinatura é o HMAC-SHA256 do id com o segredo compartilhado (base64url).
+// Não exige consulta a nenhum armazenamento.
+type HMACStore struct {
+	secret []byte
+}
+
+func NewHMACStore(secret []byte) *HMACStore {
+	return &HMACStore{secret: secret}
+}
+
+// Sign gera um token assinado para o id informado
+func (s *HMACStore) Sign(id string) string {
+	return id + "." + base64.RawURLEncoding.EncodeToString(s.signature(id))
+}
+
+func (s *HMACStore) Validate(ctx context.Context, token string) (bool, error) {
+	separator := strings.LastIndex(token, ".")
+	if separator <= 0 {
+		return false, nil
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(token[separator+1:])
+	if err != nil {
+		return false, nil
+	}
+
+	return hmac.Equal(signature, s.signature(token[:separator])), nil
+}
+
+func (s *HMACStore) signature(id string) []byte {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(id))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,62 @@
+// Package keystore valida tokens de acesso antes de aplicar o limite por token.
+package keystore
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// KeyStore valida se um token de acesso é conhecido. Implementações devem
+// ser seguras para uso concorrente.
+type KeyStore interface {
+	// Validate retorna true se o token é válido
+	Validate(ctx context.Context, token string) (bool, error)
+}
+
+// StaticStore valida tokens contra um conjunto fixo de chaves
+type StaticStore struct {
+	keys map[string]struct{}
+}
+
+func NewStaticStore(keys ...string) *StaticStore {
+	store := &StaticStore{keys: make(map[string]struct{}, len(keys))}
+	for _, key := range keys {
+		if key = strings.TrimSpace(key); key != "" {
+			store.keys[key] = struct{}{}
+		}
+	}
+	return store
+}
+
+// LoadStaticFile carrega as chaves de um arquivo com uma chave por linha.
+// Linhas vazias e iniciadas por # são ignoradas.
+func LoadStaticFile(path string) (*StaticStore, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open key store file: %w", err)
+	}
+	defer file.Close()
+
+	var keys []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		keys = append(keys, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read key store file: %w", err)
+	}
+
+	return NewStaticStore(keys...), nil
+}
+
+func (s *StaticStore) Validate(ctx context.Context, token string) (bool, error) {
+	_, exists := s.keys[token]
+	return exists, nil
+}
@@ -0,0 +1,122 @@
+package keystore
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+)
+
+func TestStaticStore_Validate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.txt")
+	content := "# chaves de parceiros\npartner-key-1\n\n  partner-key-2  \n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	store, err := LoadStaticFile(path)
+	if err != nil {
+		t.Fatalf("LoadStaticFile() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		token    string
+		expected bool
+	}{
+		{name: "Known key", token: "partner-key-1", expected: true},
+		{name: "Known key with surrounding spaces in file", token: "partner-key-2", expected: true},
+		{name: "Comment line is not a key", token: "# chaves de parceiros", expected: false},
+		{name: "Unknown key", token: "random-key", expected: false},
+		{name: "Empty key", token: "", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			valid, err := store.Validate(context.Background(), tt.token)
+			if err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			if valid != tt.expected {
+				t.Errorf("Validate(%q) = %v, expected %v", tt.token, valid, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHMACStore_Validate(t *testing.T) {
+	store := NewHMACStore([]byte("secret"))
+	other := NewHMACStore([]byte("other-secret"))
+	signed := store.Sign("customer-42")
+
+	tests := []struct {
+		name     string
+		token    string
+		expected bool
+	}{
+		{name: "Signed key", token: signed, expected: true},
+		{name: "Key signed with another secret", token: other.Sign("customer-42"), expected: false},
+		{name: "Tampered id", token: "customer-43" + signed[len("customer-42"):], expected: false},
+		{name: "Missing signature", token: "customer-42", expected: false},
+		{name: "Invalid signature encoding", token: "customer-42.!!!", expected: false},
+		{name: "Empty key", token: "", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			valid, err := store.Validate(context.Background(), tt.token)
+			if err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			if valid != tt.expected {
+				t.Errorf("Validate(%q) = %v, expected %v", tt.token, valid, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRedisSetStore_Validate(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	server.SAdd("api_keys", "partner-key-1", "partner-key-2")
+	store := NewRedisSetStore(client, "api_keys")
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		token    string
+		expected bool
+	}{
+		{name: "Key in the set", token: "partner-key-1", expected: true},
+		{name: "Unknown key", token: "random-key", expected: false},
+		{name: "Empty key", token: "", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			valid, err := store.Validate(ctx, tt.token)
+			if err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			if valid != tt.expected {
+				t.Errorf("Validate(%q) = %v, expected %v", tt.token, valid, tt.expected)
+			}
+		})
+	}
+
+	// Chaves revogadas deixam de valer sem reiniciar a aplicação
+	server.SRem("api_keys", "partner-key-1")
+	if valid, _ := store.Validate(ctx, "partner-key-1"); valid {
+		t.Error("Validate() should reject a key removed from the set")
+	}
+
+	server.Close()
+	if _, err := store.Validate(ctx, "partner-key-2"); err == nil {
+		t.Error("Validate() should return an error when Redis is unavailable")
+	}
+}
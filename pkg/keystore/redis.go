@@ -0,0 +1,25 @@
+package keystore
+
+import (
+	"context"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// RedisSetStore valida tokens verificando se pertencem a um set do Redis,
+// permitindo cadastrar e revogar chaves sem reiniciar a aplicação
+type RedisSetStore struct {
+	client *redis.Client
+	key    string
+}
+
+func NewRedisSetStore(client *redis.Client, key string) *RedisSetStore {
+	return &RedisSetStore{
+		client: client,
+		key:    key,
+	}
+}
+
+func (s *RedisSetStore) Validate(ctx context.Context, token string) (bool, error) {
+	return s.client.SIsMember(ctx, s.key, token).Result()
+}
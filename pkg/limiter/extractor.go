@@ -0,0 +1,89 @@
+package limiter
+
+import (
+	"net/http"
+	"strings"
+)
+
+// DefaultTokenHeader é o header consultado quando nenhum outro é configurado
+const DefaultTokenHeader = "API_KEY"
+
+// TokenExtractor obtém o token de acesso de uma requisição. Retorna string
+// vazia quando a requisição não possui token.
+type TokenExtractor func(r *http.Request) string
+
+// HeaderExtractor lê o token do primeiro header preenchido, na ordem informada.
+// O header Authorization é tratado como "Authorization: Bearer <token>".
+func HeaderExtractor(names ...string) TokenExtractor {
+	return func(r *http.Request) string {
+		for _, name := range names {
+			value := r.Header.Get(name)
+			if strings.EqualFold(name, "Authorization") {
+				value = bearerToken(value)
+			}
+			if value = strings.TrimSpace(value); value != "" {
+				return value
+			}
+		}
+		return ""
+	}
+}
+
+// BearerExtractor lê o token do header "Authorization: Bearer <token>"
+func BearerExtractor() TokenExtractor {
+	return HeaderExtractor("Authorization")
+}
+
+// QueryExtractor lê o token de um parâmetro da query string
+func QueryExtractor(param string) TokenExtractor {
+	return func(r *http.Request) string {
+		return strings.TrimSpace(r.URL.Query().Get(param))
+	}
+}
+
+// ChainExtractors consulta os extratores em ordem e retorna o primeiro token encontrado
+func ChainExtractors(extractors ...TokenExtractor) TokenExtractor {
+	return func(r *http.Request) string {
+		for _, extract := range extractors {
+			if token := extract(r); token != "" {
+				return token
+			}
+		}
+		return ""
+	}
+}
+
+// WithTokenExtractor substitui a extração de token padrão (headers e query
+// configurados) por uma função própria
+func WithTokenExtractor(extractor TokenExtractor) Option {
+	return func(rl *RateLimiter) {
+		rl.tokenExtractor = extractor
+	}
+}
+
+// defaultTokenExtractor monta a extração a partir da configuração: headers
+// em ordem e, por último, o parâmetro de query, se configurado
+func defaultTokenExtractor(config *Config) TokenExtractor {
+	headers := []string{DefaultTokenHeader}
+	var queryParam string
+	if config != nil {
+		if len(config.TokenHeaders) > 0 {
+			headers = config.TokenHeaders
+		}
+		queryParam = config.TokenQueryParam
+	}
+
+	extractors := []TokenExtractor{HeaderExtractor(headers...)}
+	if queryParam != "" {
+		extractors = append(extractors, QueryExtractor(queryParam))
+	}
+	return ChainExtractors(extractors...)
+}
+
+func bearerToken(authorization string) string {
+	scheme, token, found := strings.Cut(strings.TrimSpace(authorization), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return token
+}
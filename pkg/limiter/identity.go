@@ -0,0 +1,51 @@
+package limiter
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/m4rcelotoledo/rate-limiter/pkg/access"
+)
+
+// Identity é o cliente de uma requisição e o limite aplicado a ele
+type Identity struct {
+	ClientIP string
+	// Token é o token extraído da requisição, antes da validação
+	Token string
+	// Access é a decisão das listas de acesso. Com access.Allow ou
+	// access.Deny, o limite não é aplicado e os campos abaixo ficam vazios.
+	Access access.Decision
+	// Identifier e LimitType são os argumentos de CheckLimitWithTier: o token
+	// validado ("token") ou, sem token reconhecido, o IP do cliente ("ip")
+	Identifier string
+	LimitType  string
+	Tier       string
+}
+
+// Identify extrai o token e o IP da requisição, consulta as listas de acesso
+// e valida o token (ver ResolveToken), sem contabilizar a requisição. O erro
+// de ResolveToken (ex: ErrUnknownToken) é retornado junto com a identidade
+// parcial.
+func (rl *RateLimiter) Identify(ctx context.Context, r *http.Request) (*Identity, error) {
+	identity := &Identity{
+		ClientIP: rl.GetClientIP(r),
+		Token:    rl.ExtractTokenFromHeader(r),
+	}
+
+	identity.Access = rl.CheckAccess(identity.ClientIP, identity.Token)
+	if identity.Access != access.None {
+		return identity, nil
+	}
+
+	tokenIdentifier, tier, err := rl.ResolveToken(ctx, identity.Token)
+	if err != nil {
+		return identity, err
+	}
+
+	// Token reconhecido tem prioridade sobre o IP
+	identity.Identifier, identity.LimitType = identity.ClientIP, "ip"
+	if tokenIdentifier != "" {
+		identity.Identifier, identity.LimitType, identity.Tier = tokenIdentifier, "token", tier
+	}
+	return identity, nil
+}
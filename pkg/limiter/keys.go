@@ -0,0 +1,89 @@
+package limiter
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// KeyIdentifier retorna o identificador usado nas chaves do storage: IPs são
+// normalizados (ver NormalizeIP) e agregados pelo prefixo de rede
+// configurado, e tokens são substituídos pelo
+// HMAC-SHA256 com TokenHashSecret, para que chaves de API não fiquem legíveis
+// no storage. Sem segredo configurado, tokens são usados sem alteração.
+func (rl *RateLimiter) KeyIdentifier(identifier string, limitType string) string {
+	switch limitType {
+	case "ip":
+		return rl.IPIdentifier(NormalizeIP(identifier))
+	case "token":
+		if rl.config.TokenHashSecret == "" {
+			return identifier
+		}
+		mac := hmac.New(sha256.New, []byte(rl.config.TokenHashSecret))
+		mac.Write([]byte(identifier))
+		return hex.EncodeToString(mac.Sum(nil))
+	default:
+		return identifier
+	}
+}
+
+// StorageKeys retorna as chaves de contador e de bloqueio de um identificador,
+// prefixadas por KeyNamespace quando configurado
+// (ex: checkout:rate_limit:ip:192.168.1.1)
+func (rl *RateLimiter) StorageKeys(identifier string, limitType string) (string, string) {
+	return rl.storageKeysFor(rl.KeyIdentifier(identifier, limitType), limitType)
+}
+
+// storageKeysFor retorna as chaves de contador e de bloqueio a partir do
+// identificador já convertido por KeyIdentifier
+func (rl *RateLimiter) storageKeysFor(keyIdentifier string, limitType string) (string, string) {
+	return rl.namespaced(fmt.Sprintf("rate_limit:%s:%s", limitType, keyIdentifier)),
+		rl.namespaced(fmt.Sprintf("block:%s:%s", limitType, keyIdentifier))
+}
+
+// shadowBlockKey retorna a chave do bloqueio simulado por regras em shadow
+// mode (ex: shadow_block:ip:192.168.1.1), fora do prefixo listado por ScanBlocks
+func (rl *RateLimiter) shadowBlockKey(keyIdentifier string, limitType string) string {
+	return rl.namespaced(fmt.Sprintf("shadow_block:%s:%s", limitType, keyIdentifier))
+}
+
+// legacyBlockKey retorna a chave de bloqueio no formato anterior ao namespace
+// e ao hash de tokens (block:<tipo>:<identificador>)
+func legacyBlockKey(identifier string, limitType string) string {
+	return fmt.Sprintf("block:%s:%s", limitType, identifier)
+}
+
+func (rl *RateLimiter) namespaced(key string) string {
+	if rl.config.KeyNamespace == "" {
+		return key
+	}
+	return rl.config.KeyNamespace + ":" + key
+}
+
+// activeBlockKey retorna a chave de bloqueio ativa do identificador, ou
+// string vazia se não estiver bloqueado. Durante a migração de chaves
+// (LegacyKeyFallback), também consulta a chave no formato antigo, para que
+// bloqueios criados antes da mudança de namespace ou do hash continuem
+// valendo até expirar.
+func (rl *RateLimiter) activeBlockKey(ctx context.Context, identifier string, limitType string, blockKey string) (string, error) {
+	isBlocked, err := rl.storage.Exists(ctx, blockKey)
+	if err != nil {
+		return "", err
+	}
+	if isBlocked {
+		return blockKey, nil
+	}
+
+	legacyKey := legacyBlockKey(identifier, limitType)
+	if !rl.config.LegacyKeyFallback || legacyKey == blockKey {
+		return "", nil
+	}
+
+	isBlocked, err = rl.storage.Exists(ctx, legacyKey)
+	if err != nil || !isBlocked {
+		return "", err
+	}
+	return legacyKey, nil
+}
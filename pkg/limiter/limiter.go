@@ -0,0 +1,597 @@
+// Package limiter implementa o rate limiter por IP e por token, independente
+// de framework web e de storage (ver StorageStrategy).
+package limiter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"time"
+
+	"github.com/m4rcelotoledo/rate-limiter/pkg/access"
+	"github.com/m4rcelotoledo/rate-limiter/pkg/audit"
+	"github.com/m4rcelotoledo/rate-limiter/pkg/heavyhitters"
+	"github.com/m4rcelotoledo/rate-limiter/pkg/keystore"
+	"github.com/m4rcelotoledo/rate-limiter/pkg/metrics"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/m4rcelotoledo/rate-limiter/pkg/limiter"
+
+// ErrUnknownToken indica que o token não foi reconhecido pelo key store ou
+// pelo verificador de tokens e a configuração exige que a requisição seja rejeitada
+var ErrUnknownToken = errors.New("unknown API key")
+
+type RateLimiter struct {
+	storage        StorageStrategy
+	config         *Config
+	accessList     *access.List
+	tokenExtractor TokenExtractor
+	keyStore       keystore.KeyStore
+	tokenVerifier  TokenVerifier
+	metrics        *metrics.Metrics
+	tracer         trace.Tracer
+	logger         *slog.Logger
+	auditEmitter   *audit.Emitter
+	heavyHitters   heavyhitters.Tracker
+	decisions      decisionCounters
+	// allowedLogSampleRate é a fração das requisições permitidas registrada no log
+	allowedLogSampleRate float64
+	// plainIPLogging registra IPs em texto puro nos logs e na auditoria
+	plainIPLogging bool
+}
+
+type Config struct {
+	IPRequestsPerSecond       int
+	IPBlockDurationSeconds    int
+	TokenRequestsPerSecond    int
+	TokenBlockDurationSeconds int
+	// IPv4PrefixLength e IPv6PrefixLength agregam os IPs por prefixo de rede
+	// (ex: 24 para /24 em IPv4, 64 para /64 em IPv6). Zero desativa a agregação.
+	IPv4PrefixLength int
+	IPv6PrefixLength int
+	// TokenHeaders são os headers consultados, em ordem, para obter o token
+	// (vazio usa API_KEY). Authorization é lido como "Bearer <token>".
+	TokenHeaders []string
+	// TokenQueryParam é o parâmetro de query usado quando nenhum header
+	// contém token. Vazio desativa a leitura pela query.
+	TokenQueryParam string
+	// RejectUnknownTokens rejeita tokens não reconhecidos pelo key store. Se
+	// false, requisições com tokens desconhecidos caem no limite por IP.
+	RejectUnknownTokens bool
+	// Tiers define limites alternativos por token, selecionados pelo plano do
+	// cliente (ex: claim de plano do JWT). Tiers desconhecidos usam o limite por token.
+	Tiers map[string]Tier
+	// TokenHashSecret é a chave do HMAC aplicado aos tokens antes de usá-los
+	// nas chaves do storage. Vazio mantém os tokens em texto puro.
+	TokenHashSecret string
+	// KeyNamespace prefixa todas as chaves do storage, isolando aplicações
+	// que compartilham o mesmo Redis (ex: "checkout" ou "prod:checkout")
+	KeyNamespace string
+	// LegacyKeyFallback também consulta as chaves de bloqueio no formato sem
+	// namespace e sem hash, para migrar sem perder bloqueios ativos
+	LegacyKeyFallback bool
+	// TrustedProxies são as redes dos proxies (ex: o load balancer) dos quais
+	// X-Forwarded-For, X-Real-IP e X-Client-IP são aceitos. Sem proxies
+	// confiáveis, o IP do cliente é sempre o endereço remoto da conexão.
+	TrustedProxies []netip.Prefix
+	// ShadowRules definem limites candidatos por regra ("ip", "token" ou
+	// "token:<tier>"), avaliados sobre o mesmo contador do limite aplicado
+	// para medir o efeito de uma mudança: a regra continua aplicando o limite
+	// atual, e as negações e bloqueios do candidato são apenas registrados
+	// (ver LimitResult.Shadow).
+	ShadowRules map[string]Tier
+}
+
+// Tier define os limites de um plano de clientes
+type Tier struct {
+	RequestsPerSecond    int
+	BlockDurationSeconds int
+}
+
+// TokenVerifier verifica tokens autocontidos (ex: JWT) e retorna o
+// identificador do cliente e o tier de limite indicado no token
+type TokenVerifier interface {
+	Verify(ctx context.Context, token string) (identifier string, tier string, err error)
+}
+
+type StorageStrategy interface {
+	Increment(ctx context.Context, key string, expiration time.Duration) (int64, error)
+	Get(ctx context.Context, key string) (int64, error)
+	Set(ctx context.Context, key string, value int64, expiration time.Duration) error
+	Exists(ctx context.Context, key string) (bool, error)
+	Delete(ctx context.Context, key string) error
+	TTL(ctx context.Context, key string) (time.Duration, error)
+	Scan(ctx context.Context, cursor uint64, match string, count int64) ([]string, uint64, error)
+	Close() error
+}
+
+// Option configura componentes opcionais do RateLimiter
+type Option func(*RateLimiter)
+
+// WithAccessList define as listas de IPs/tokens permitidos e bloqueados,
+// consultadas antes da verificação de limite
+func WithAccessList(list *access.List) Option {
+	return func(rl *RateLimiter) {
+		rl.accessList = list
+	}
+}
+
+// WithKeyStore exige que os tokens sejam validados pelo key store antes de
+// receberem o limite por token
+func WithKeyStore(store keystore.KeyStore) Option {
+	return func(rl *RateLimiter) {
+		rl.keyStore = store
+	}
+}
+
+// WithTokenVerifier identifica os clientes a partir de tokens verificáveis
+// (ex: JWT), usando o identificador e o tier retornados pelo verificador
+func WithTokenVerifier(verifier TokenVerifier) Option {
+	return func(rl *RateLimiter) {
+		rl.tokenVerifier = verifier
+	}
+}
+
+// WithMetrics registra as decisões e a latência das verificações nas
+// métricas Prometheus informadas, que também passam a contar os bloqueios
+// ativos no storage
+func WithMetrics(m *metrics.Metrics) Option {
+	return func(rl *RateLimiter) {
+		rl.metrics = m
+	}
+}
+
+// WithTracerProvider define o provider dos spans OpenTelemetry das
+// verificações de limite (padrão: provider global)
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(rl *RateLimiter) {
+		rl.tracer = provider.Tracer(instrumentationName)
+	}
+}
+
+// WithAuditEmitter registra um evento de auditoria a cada bloqueio criado
+func WithAuditEmitter(emitter *audit.Emitter) Option {
+	return func(rl *RateLimiter) {
+		rl.auditEmitter = emitter
+	}
+}
+
+// WithHeavyHitters registra cada decisão no tracker de maiores consumidores
+func WithHeavyHitters(tracker heavyhitters.Tracker) Option {
+	return func(rl *RateLimiter) {
+		rl.heavyHitters = tracker
+	}
+}
+
+// NewRateLimiter cria o rate limiter a partir de uma Config. As opções de
+// configuração (ex: WithIPLimit) alteram a Config informada; config nil usa
+// DefaultConfig. Ver também New.
+func NewRateLimiter(storage StorageStrategy, config *Config, opts ...Option) *RateLimiter {
+	if config == nil {
+		config = DefaultConfig()
+	}
+
+	rl := &RateLimiter{
+		storage: storage,
+		config:  config,
+	}
+	for _, opt := range opts {
+		opt(rl)
+	}
+	if rl.tokenExtractor == nil {
+		rl.tokenExtractor = defaultTokenExtractor(rl.config)
+	}
+	if rl.tracer == nil {
+		rl.tracer = otel.GetTracerProvider().Tracer(instrumentationName)
+	}
+	rl.metrics.CountBlocks(rl.countBlocks)
+	return rl
+}
+
+type LimitResult struct {
+	Allowed   bool
+	Limit     int64
+	Remaining int64
+	ResetTime time.Time
+	// Window é a duração da janela de contagem
+	Window time.Duration
+	// RetryAfter é o tempo restante de bloqueio quando a requisição é negada
+	RetryAfter time.Duration
+	// Rule é a regra de limite aplicada: "ip", "token" ou "token:<tier>"
+	Rule string
+	// Shadow indica que a regra tem um limite candidato em shadow mode, que
+	// foi avaliado sem alterar Allowed
+	Shadow bool
+	// ShadowLimit é o limite candidato da regra em shadow mode
+	ShadowLimit int64
+	// ShadowDenied indica que a requisição seria negada com o limite
+	// candidato; ShadowRetryAfter é o bloqueio simulado restante
+	ShadowDenied     bool
+	ShadowRetryAfter time.Duration
+}
+
+func (rl *RateLimiter) CheckLimit(ctx context.Context, identifier string, limitType string) (*LimitResult, error) {
+	return rl.CheckLimitWithTier(ctx, identifier, limitType, "")
+}
+
+// CheckLimitWithTier verifica o limite usando os valores do tier informado,
+// quando configurado. O tier altera apenas os limites, não a chave no storage,
+// então mudanças de plano valem imediatamente para o mesmo contador.
+func (rl *RateLimiter) CheckLimitWithTier(ctx context.Context, identifier string, limitType string, tier string) (*LimitResult, error) {
+	ctx, span := rl.tracer.Start(ctx, "rate_limiter.check_limit",
+		trace.WithAttributes(attribute.String("rate_limiter.limit_type", limitType)),
+	)
+	defer span.End()
+
+	start := time.Now()
+	result, err := rl.checkLimit(ctx, identifier, limitType, tier)
+	rl.metrics.ObserveCheck(limitType, time.Since(start))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	rl.metrics.ObserveDecision(limitType, result.Rule, result.Allowed)
+	rl.decisions.add(limitType, result.Allowed)
+	span.SetAttributes(
+		attribute.String("rate_limiter.rule", result.Rule),
+		attribute.Bool("rate_limiter.allowed", result.Allowed),
+		attribute.Int64("rate_limiter.limit", result.Limit),
+		attribute.Int64("rate_limiter.remaining", result.Remaining),
+	)
+	if result.Shadow {
+		span.SetAttributes(attribute.Bool("rate_limiter.shadow_denied", result.ShadowDenied))
+		if result.ShadowDenied {
+			rl.metrics.ObserveShadowDenial(limitType, result.Rule)
+		}
+	}
+	return result, nil
+}
+
+// limits retorna o limite, a duração do bloqueio e a regra aplicados ao tipo
+// de limite e ao tier informados
+func (rl *RateLimiter) limits(limitType string, tier string) (requestsPerSecond int, blockDurationSeconds int, rule string, err error) {
+	rule = limitType
+
+	switch limitType {
+	case "ip":
+		requestsPerSecond = rl.config.IPRequestsPerSecond
+		blockDurationSeconds = rl.config.IPBlockDurationSeconds
+	case "token":
+		requestsPerSecond = rl.config.TokenRequestsPerSecond
+		blockDurationSeconds = rl.config.TokenBlockDurationSeconds
+		if limits, ok := rl.config.Tiers[tier]; ok && tier != "" {
+			requestsPerSecond = limits.RequestsPerSecond
+			blockDurationSeconds = limits.BlockDurationSeconds
+			rule = "token:" + tier
+		}
+	default:
+		return 0, 0, "", fmt.Errorf("invalid limit type: %s", limitType)
+	}
+	return requestsPerSecond, blockDurationSeconds, rule, nil
+}
+
+func (rl *RateLimiter) checkLimit(ctx context.Context, identifier string, limitType string, tier string) (*LimitResult, error) {
+	requestsPerSecond, blockDurationSeconds, rule, err := rl.limits(limitType, tier)
+	if err != nil {
+		return nil, err
+	}
+
+	// Chaves para o storage
+	key, blockKey := rl.StorageKeys(identifier, limitType)
+
+	// Duração da janela de tempo (1 segundo)
+	windowDuration := time.Second
+	// Duração do bloqueio
+	blockDuration := time.Duration(blockDurationSeconds) * time.Second
+
+	// Verifica se está bloqueado e, se estiver, quanto falta para o bloqueio expirar
+	activeBlockKey, err := rl.activeBlockKey(ctx, identifier, limitType, blockKey)
+	if err != nil {
+		return nil, fmt.Errorf("error checking block status: %w", err)
+	}
+
+	if activeBlockKey != "" {
+		remaining, err := rl.storage.TTL(ctx, activeBlockKey)
+		if err != nil {
+			return nil, fmt.Errorf("error reading block expiration: %w", err)
+		}
+		// Bloqueios sem expiração informam a duração configurada como estimativa
+		if remaining <= 0 {
+			remaining = blockDuration
+		}
+
+		result := &LimitResult{
+			Allowed:    false,
+			Limit:      int64(requestsPerSecond),
+			Remaining:  0,
+			ResetTime:  time.Now().Add(remaining),
+			Window:     windowDuration,
+			RetryAfter: remaining,
+			Rule:       rule,
+		}
+		rl.recordDecision(ctx, decisionLog{identifier: identifier, limitType: limitType, result: result, count: -1})
+		return result, nil
+	}
+
+	// Incrementa o contador
+	currentCount, err := rl.storage.Increment(ctx, key, windowDuration)
+	if err != nil {
+		return nil, fmt.Errorf("error incrementing counter: %w", err)
+	}
+
+	// Verifica se excedeu o limite
+	if currentCount > int64(requestsPerSecond) {
+		// Define o bloqueio
+		err = rl.storage.Set(ctx, blockKey, 1, blockDuration)
+		if err != nil {
+			return nil, fmt.Errorf("error setting block: %w", err)
+		}
+
+		result := &LimitResult{
+			Allowed:    false,
+			Limit:      int64(requestsPerSecond),
+			Remaining:  0,
+			ResetTime:  time.Now().Add(blockDuration),
+			Window:     windowDuration,
+			RetryAfter: blockDuration,
+			Rule:       rule,
+		}
+		shadowBlockCreated, err := rl.checkShadowLimit(ctx, identifier, limitType, result, currentCount)
+		if err != nil {
+			return nil, err
+		}
+		rl.recordDecision(ctx, decisionLog{identifier: identifier, limitType: limitType, result: result, count: currentCount, blockCreated: true, shadowBlockCreated: shadowBlockCreated})
+		rl.auditEmitter.Emit(ctx, audit.Event{
+			Type:       audit.BlockEvent,
+			Source:     audit.SourceLimiter,
+			LimitType:  limitType,
+			Identifier: rl.RedactedIdentifier(identifier, limitType),
+			Rule:       rule,
+			Duration:   blockDuration,
+			ExpiresAt:  &result.ResetTime,
+			Reason:     fmt.Sprintf("%d requests exceeded limit of %d", currentCount, requestsPerSecond),
+		})
+		return result, nil
+	}
+
+	result := &LimitResult{
+		Allowed:   true,
+		Limit:     int64(requestsPerSecond),
+		Remaining: int64(requestsPerSecond) - currentCount,
+		ResetTime: time.Now().Add(windowDuration),
+		Window:    windowDuration,
+		Rule:      rule,
+	}
+	shadowBlockCreated, err := rl.checkShadowLimit(ctx, identifier, limitType, result, currentCount)
+	if err != nil {
+		return nil, err
+	}
+	rl.recordDecision(ctx, decisionLog{identifier: identifier, limitType: limitType, result: result, count: currentCount, shadowBlockCreated: shadowBlockCreated})
+	return result, nil
+}
+
+// checkShadowLimit avalia o limite candidato da regra em shadow mode, se
+// houver, sobre o contador já incrementado por checkLimit. Ao exceder o
+// candidato, cria um bloqueio simulado (chave shadow_block), e as requisições
+// durante ele são marcadas com ShadowDenied; a decisão real não é alterada.
+// Durante um bloqueio real o contador não é incrementado e o candidato não é
+// avaliado. Retorna se o bloqueio simulado foi criado nesta requisição.
+func (rl *RateLimiter) checkShadowLimit(ctx context.Context, identifier string, limitType string, result *LimitResult, count int64) (bool, error) {
+	candidate, ok := rl.config.ShadowRules[result.Rule]
+	if !ok {
+		return false, nil
+	}
+
+	limit := int64(candidate.RequestsPerSecond)
+	blockDuration := time.Duration(candidate.BlockDurationSeconds) * time.Second
+	shadowBlockKey := rl.shadowBlockKey(rl.KeyIdentifier(identifier, limitType), limitType)
+	result.Shadow = true
+	result.ShadowLimit = limit
+
+	isBlocked, err := rl.storage.Exists(ctx, shadowBlockKey)
+	if err != nil {
+		return false, fmt.Errorf("error checking shadow block status: %w", err)
+	}
+	if isBlocked {
+		remaining, err := rl.storage.TTL(ctx, shadowBlockKey)
+		if err != nil {
+			return false, fmt.Errorf("error reading shadow block expiration: %w", err)
+		}
+		if remaining <= 0 {
+			remaining = blockDuration
+		}
+		result.ShadowDenied = true
+		result.ShadowRetryAfter = remaining
+		return false, nil
+	}
+
+	if count > limit {
+		if err := rl.storage.Set(ctx, shadowBlockKey, 1, blockDuration); err != nil {
+			return false, fmt.Errorf("error setting shadow block: %w", err)
+		}
+		result.ShadowDenied = true
+		result.ShadowRetryAfter = blockDuration
+		return true, nil
+	}
+	return false, nil
+}
+
+// recordDecision registra a decisão no log e no tracker de maiores consumidores
+func (rl *RateLimiter) recordDecision(ctx context.Context, decision decisionLog) {
+	rl.logDecision(ctx, decision)
+	if rl.heavyHitters != nil {
+		rl.heavyHitters.Record(decision.limitType, rl.KeyIdentifier(decision.identifier, decision.limitType), decision.count, decision.result.Allowed)
+	}
+}
+
+// ResolveToken valida o token e retorna o identificador e o tier que devem
+// receber o limite por token. Com verificador configurado (ex: JWT), o
+// identificador vem das claims do token; com key store, o token precisa ser
+// conhecido. Tokens não reconhecidos resultam em identificador vazio (limite
+// por IP) ou em ErrUnknownToken, se RejectUnknownTokens estiver ativo. Sem
+// verificador nem key store, o token é aceito sem validação.
+func (rl *RateLimiter) ResolveToken(ctx context.Context, token string) (string, string, error) {
+	if token == "" || (rl.tokenVerifier == nil && rl.keyStore == nil) {
+		return token, "", nil
+	}
+
+	if rl.tokenVerifier != nil {
+		if identifier, tier, err := rl.tokenVerifier.Verify(ctx, token); err == nil {
+			return identifier, tier, nil
+		}
+	}
+
+	if rl.keyStore != nil {
+		valid, err := rl.keyStore.Validate(ctx, token)
+		if err != nil {
+			return "", "", fmt.Errorf("error validating token: %w", err)
+		}
+		if valid {
+			return token, "", nil
+		}
+	}
+
+	if rl.config.RejectUnknownTokens {
+		return "", "", ErrUnknownToken
+	}
+	return "", "", nil
+}
+
+// CheckAccess consulta as listas de acesso para o IP e o token do cliente.
+// Sem listas configuradas, retorna sempre access.None.
+func (rl *RateLimiter) CheckAccess(ip, token string) access.Decision {
+	if rl.accessList == nil {
+		return access.None
+	}
+	return rl.accessList.Check(ip, token)
+}
+
+// AuditEmitter retorna o destino dos eventos de auditoria (nil se não houver)
+func (rl *RateLimiter) AuditEmitter() *audit.Emitter {
+	return rl.auditEmitter
+}
+
+// HeavyHitters retorna o tracker de maiores consumidores (nil se não houver)
+func (rl *RateLimiter) HeavyHitters() heavyhitters.Tracker {
+	return rl.heavyHitters
+}
+
+// AccessList retorna as listas de acesso configuradas (nil se não houver)
+func (rl *RateLimiter) AccessList() *access.List {
+	return rl.accessList
+}
+
+// IPIdentifier retorna o identificador usado nas chaves de limite por IP,
+// agregando o endereço ao prefixo de rede configurado (ex: 2001:db8::/64).
+// Endereços inválidos ou sem agregação configurada são retornados sem alteração.
+func (rl *RateLimiter) IPIdentifier(ip string) string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ip
+	}
+
+	prefixLength := rl.config.IPv6PrefixLength
+	if addr.Is4() {
+		prefixLength = rl.config.IPv4PrefixLength
+	}
+	if prefixLength <= 0 || prefixLength >= addr.BitLen() {
+		return ip
+	}
+
+	prefix, err := addr.Prefix(prefixLength)
+	if err != nil {
+		return ip
+	}
+	return prefix.String()
+}
+
+// ExtractTokenFromHeader obtém o token de acesso da requisição usando o
+// extrator configurado (por padrão, os headers e a query da configuração)
+func (rl *RateLimiter) ExtractTokenFromHeader(r *http.Request) string {
+	if rl.tokenExtractor == nil {
+		return defaultTokenExtractor(rl.config)(r)
+	}
+	return rl.tokenExtractor(r)
+}
+
+// GetClientIP retorna o IP do cliente. Os headers de encaminhamento só são
+// considerados quando a conexão vem de um proxy confiável (ver
+// Config.TrustedProxies); de qualquer outro cliente, eles permitiriam escolher
+// o próprio IP e escapar do limite, da denylist ou entrar na allowlist.
+func (rl *RateLimiter) GetClientIP(r *http.Request) string {
+	remoteIP := NormalizeIP(r.RemoteAddr)
+	if !rl.trustedProxy(remoteIP) {
+		return remoteIP
+	}
+
+	// Cada proxy acrescenta ao X-Forwarded-For o endereço de quem o chamou:
+	// percorrendo da direita para a esquerda, o primeiro endereço fora dos
+	// proxies confiáveis é o do cliente, e os anteriores podem ser forjados
+	if values := r.Header.Values("X-Forwarded-For"); len(values) > 0 {
+		hops := strings.Split(strings.Join(values, ","), ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			ip := NormalizeIP(hops[i])
+			if ip != "" && (i == 0 || !rl.trustedProxy(ip)) {
+				return ip
+			}
+		}
+	}
+	if ip := r.Header.Get("X-Real-IP"); ip != "" {
+		return NormalizeIP(ip)
+	}
+	if ip := r.Header.Get("X-Client-IP"); ip != "" {
+		return NormalizeIP(ip)
+	}
+
+	return remoteIP
+}
+
+// trustedProxy indica se o IP está numa rede de Config.TrustedProxies
+func (rl *RateLimiter) trustedProxy(ip string) bool {
+	if rl.config == nil {
+		return false
+	}
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	for _, prefix := range rl.config.TrustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeIP converte um endereço para a forma canônica do IP: remove a porta
+// (inclusive de endereços IPv6 entre colchetes), converte IPv4 mapeado em IPv6
+// para IPv4 e escreve IPv6 em minúsculas na forma comprimida. Valores que não
+// são IPs válidos são retornados apenas sem espaços.
+func NormalizeIP(address string) string {
+	address = strings.TrimSpace(address)
+
+	// Remove a porta, se houver (ex: 192.168.1.50:8080 ou [::1]:8080)
+	if host, _, err := net.SplitHostPort(address); err == nil {
+		address = host
+	}
+	address = strings.TrimSuffix(strings.TrimPrefix(address, "["), "]")
+
+	ip, err := netip.ParseAddr(address)
+	if err != nil {
+		return address
+	}
+
+	return ip.Unmap().WithZone("").String()
+}
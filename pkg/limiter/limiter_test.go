@@ -0,0 +1,1435 @@
+package limiter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/m4rcelotoledo/rate-limiter/pkg/audit"
+	"github.com/m4rcelotoledo/rate-limiter/pkg/heavyhitters"
+	"github.com/m4rcelotoledo/rate-limiter/pkg/keystore"
+	"github.com/m4rcelotoledo/rate-limiter/pkg/metrics"
+	"github.com/m4rcelotoledo/rate-limiter/pkg/storage"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// newTestStorage cria o MemoryStorage usado nos testes, fechado no fim do teste
+func newTestStorage(t testing.TB) *storage.MemoryStorage {
+	t.Helper()
+	memoryStorage := storage.NewMemoryStorage()
+	t.Cleanup(func() { memoryStorage.Close() })
+	return memoryStorage
+}
+
+func TestRateLimiter_CheckLimit(t *testing.T) {
+	config := &Config{
+		IPRequestsPerSecond:       5,
+		IPBlockDurationSeconds:    60,
+		TokenRequestsPerSecond:    10,
+		TokenBlockDurationSeconds: 120,
+	}
+
+	memoryStorage := newTestStorage(t)
+	limiter := NewRateLimiter(memoryStorage, config)
+
+	tests := []struct {
+		name       string
+		identifier string
+		limitType  string
+		requests   int
+		expected   bool
+	}{
+		{
+			name:       "IP limit not exceeded",
+			identifier: "192.168.1.1",
+			limitType:  "ip",
+			requests:   3,
+			expected:   true,
+		},
+		{
+			name:       "IP limit exceeded",
+			identifier: "192.168.1.2",
+			limitType:  "ip",
+			requests:   6,
+			expected:   false,
+		},
+		{
+			name:       "Token limit not exceeded",
+			identifier: "abc123",
+			limitType:  "token",
+			requests:   8,
+			expected:   true,
+		},
+		{
+			name:       "Token limit exceeded",
+			identifier: "def456",
+			limitType:  "token",
+			requests:   12,
+			expected:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			// Simula múltiplas requisições
+			for i := 0; i < tt.requests; i++ {
+				result, err := limiter.CheckLimit(ctx, tt.identifier, tt.limitType)
+				if err != nil {
+					t.Errorf("CheckLimit() error = %v", err)
+					return
+				}
+
+				// Verifica se o resultado está correto na última requisição
+				if i == tt.requests-1 {
+					if result.Allowed != tt.expected {
+						t.Errorf("CheckLimit() = %v, expected %v", result.Allowed, tt.expected)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimiter_ExtractTokenFromHeader(t *testing.T) {
+	limiter := &RateLimiter{}
+
+	tests := []struct {
+		name     string
+		headers  map[string]string
+		expected string
+	}{
+		{
+			name:     "No API_KEY header",
+			headers:  map[string]string{},
+			expected: "",
+		},
+		{
+			name:     "API_KEY header present",
+			headers:  map[string]string{"API_KEY": "test-token-123"},
+			expected: "test-token-123",
+		},
+		{
+			name:     "API_KEY header with spaces",
+			headers:  map[string]string{"API_KEY": "  test-token-456  "},
+			expected: "test-token-456",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/", nil)
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+
+			result := limiter.ExtractTokenFromHeader(req)
+			if result != tt.expected {
+				t.Errorf("ExtractTokenFromHeader() = %v, expected %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRateLimiter_ExtractTokenFromHeaderConfigured(t *testing.T) {
+	config := &Config{
+		TokenHeaders:    []string{"X-API-Key", "Authorization"},
+		TokenQueryParam: "api_key",
+	}
+	limiter := NewRateLimiter(newTestStorage(t), config)
+
+	tests := []struct {
+		name     string
+		url      string
+		headers  map[string]string
+		expected string
+	}{
+		{
+			name:     "Custom header",
+			url:      "/",
+			headers:  map[string]string{"X-API-Key": "custom-123"},
+			expected: "custom-123",
+		},
+		{
+			name:     "Authorization Bearer",
+			url:      "/",
+			headers:  map[string]string{"Authorization": "Bearer bearer-123"},
+			expected: "bearer-123",
+		},
+		{
+			name:     "Authorization Bearer scheme is case-insensitive",
+			url:      "/",
+			headers:  map[string]string{"Authorization": "bearer  bearer-456 "},
+			expected: "bearer-456",
+		},
+		{
+			name:     "Authorization with other scheme is ignored",
+			url:      "/",
+			headers:  map[string]string{"Authorization": "Basic dXNlcjpwYXNz"},
+			expected: "",
+		},
+		{
+			name:     "Header order defines priority",
+			url:      "/",
+			headers:  map[string]string{"X-API-Key": "custom-123", "Authorization": "Bearer bearer-123"},
+			expected: "custom-123",
+		},
+		{
+			name:     "Query parameter fallback",
+			url:      "/?api_key=query-123",
+			headers:  map[string]string{},
+			expected: "query-123",
+		},
+		{
+			name:     "Header takes precedence over query parameter",
+			url:      "/?api_key=query-123",
+			headers:  map[string]string{"X-API-Key": "custom-123"},
+			expected: "custom-123",
+		},
+		{
+			name:     "Unconfigured API_KEY header is ignored",
+			url:      "/",
+			headers:  map[string]string{"API_KEY": "legacy-123"},
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", tt.url, nil)
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+
+			result := limiter.ExtractTokenFromHeader(req)
+			if result != tt.expected {
+				t.Errorf("ExtractTokenFromHeader() = %v, expected %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRateLimiter_WithTokenExtractor(t *testing.T) {
+	cookieExtractor := func(r *http.Request) string {
+		cookie, err := r.Cookie("session")
+		if err != nil {
+			return ""
+		}
+		return cookie.Value
+	}
+
+	limiter := NewRateLimiter(newTestStorage(t), &Config{}, WithTokenExtractor(
+		ChainExtractors(cookieExtractor, BearerExtractor()),
+	))
+
+	req, _ := http.NewRequest("GET", "/", nil)
+	req.AddCookie(&http.Cookie{Name: "session", Value: "cookie-123"})
+	if result := limiter.ExtractTokenFromHeader(req); result != "cookie-123" {
+		t.Errorf("ExtractTokenFromHeader() = %v, expected %v", result, "cookie-123")
+	}
+
+	req, _ = http.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "Bearer bearer-123")
+	if result := limiter.ExtractTokenFromHeader(req); result != "bearer-123" {
+		t.Errorf("ExtractTokenFromHeader() = %v, expected %v", result, "bearer-123")
+	}
+}
+
+func TestRateLimiter_GetClientIP(t *testing.T) {
+	limiter := &RateLimiter{config: &Config{
+		TrustedProxies: []netip.Prefix{netip.MustParsePrefix("127.0.0.0/8"), netip.MustParsePrefix("10.0.0.0/8")},
+	}}
+
+	tests := []struct {
+		name       string
+		headers    map[string]string
+		remoteAddr string
+		expected   string
+	}{
+		{
+			name:       "X-Forwarded-For header",
+			headers:    map[string]string{"X-Forwarded-For": "192.168.1.100"},
+			remoteAddr: "127.0.0.1:8080",
+			expected:   "192.168.1.100",
+		},
+		{
+			name:       "X-Real-IP header",
+			headers:    map[string]string{"X-Real-IP": "10.0.0.1"},
+			remoteAddr: "127.0.0.1:8080",
+			expected:   "10.0.0.1",
+		},
+		{
+			name:       "RemoteAddr fallback",
+			headers:    map[string]string{},
+			remoteAddr: "192.168.1.50:8080",
+			expected:   "192.168.1.50",
+		},
+		{
+			name:       "RemoteAddr IPv6 with brackets and port",
+			headers:    map[string]string{},
+			remoteAddr: "[2001:DB8::1]:8080",
+			expected:   "2001:db8::1",
+		},
+		{
+			name:       "RemoteAddr IPv4-mapped IPv6",
+			headers:    map[string]string{},
+			remoteAddr: "[::ffff:192.168.1.50]:8080",
+			expected:   "192.168.1.50",
+		},
+		{
+			name:       "X-Forwarded-For with trusted hops and spaces",
+			headers:    map[string]string{"X-Forwarded-For": " 203.0.113.7 , 10.0.0.1"},
+			remoteAddr: "127.0.0.1:8080",
+			expected:   "203.0.113.7",
+		},
+		{
+			name:       "X-Forwarded-For spoofed before the proxy",
+			headers:    map[string]string{"X-Forwarded-For": "10.1.2.3, 198.51.100.7"},
+			remoteAddr: "127.0.0.1:8080",
+			expected:   "198.51.100.7",
+		},
+		{
+			name:       "X-Forwarded-For from untrusted client",
+			headers:    map[string]string{"X-Forwarded-For": "10.1.2.3"},
+			remoteAddr: "198.51.100.7:8080",
+			expected:   "198.51.100.7",
+		},
+		{
+			name:       "X-Real-IP from untrusted client",
+			headers:    map[string]string{"X-Real-IP": "10.1.2.3", "X-Client-IP": "10.1.2.4"},
+			remoteAddr: "198.51.100.7:8080",
+			expected:   "198.51.100.7",
+		},
+		{
+			name:       "X-Real-IP bracketed IPv6 without port",
+			headers:    map[string]string{"X-Real-IP": "[2001:0db8:0000:0000:0000:0000:0000:00AB]"},
+			remoteAddr: "127.0.0.1:8080",
+			expected:   "2001:db8::ab",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+
+			result := limiter.GetClientIP(req)
+			if result != tt.expected {
+				t.Errorf("GetClientIP() = %v, expected %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNormalizeIP(t *testing.T) {
+	tests := []struct {
+		name     string
+		address  string
+		expected string
+	}{
+		{name: "IPv4 without port", address: "10.0.0.1", expected: "10.0.0.1"},
+		{name: "IPv4 with port", address: "10.0.0.1:443", expected: "10.0.0.1"},
+		{name: "IPv6 without brackets", address: "2001:DB8::A", expected: "2001:db8::a"},
+		{name: "IPv6 with brackets", address: "[2001:db8::a]", expected: "2001:db8::a"},
+		{name: "IPv6 with brackets and port", address: "[2001:db8::a]:443", expected: "2001:db8::a"},
+		{name: "IPv6 expanded form", address: "2001:0db8:0000:0000:0000:0000:0000:000a", expected: "2001:db8::a"},
+		{name: "IPv6 with zone", address: "[fe80::1%eth0]:8080", expected: "fe80::1"},
+		{name: "IPv4-mapped IPv6", address: "::ffff:10.0.0.1", expected: "10.0.0.1"},
+		{name: "Invalid address", address: " not-an-ip ", expected: "not-an-ip"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := NormalizeIP(tt.address)
+			if result != tt.expected {
+				t.Errorf("NormalizeIP(%q) = %v, expected %v", tt.address, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRateLimiter_IPIdentifier(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   *Config
+		ip       string
+		expected string
+	}{
+		{
+			name:     "No aggregation configured",
+			config:   &Config{},
+			ip:       "2001:db8:1:2:3:4:5:6",
+			expected: "2001:db8:1:2:3:4:5:6",
+		},
+		{
+			name:     "IPv6 aggregated by /64",
+			config:   &Config{IPv6PrefixLength: 64},
+			ip:       "2001:db8:1:2:3:4:5:6",
+			expected: "2001:db8:1:2::/64",
+		},
+		{
+			name:     "IPv6 aggregated by /56",
+			config:   &Config{IPv6PrefixLength: 56},
+			ip:       "2001:db8:1:2ff:3:4:5:6",
+			expected: "2001:db8:1:200::/56",
+		},
+		{
+			name:     "IPv4 aggregated by /24",
+			config:   &Config{IPv4PrefixLength: 24, IPv6PrefixLength: 64},
+			ip:       "192.168.1.50",
+			expected: "192.168.1.0/24",
+		},
+		{
+			name:     "IPv4 not affected by IPv6 prefix",
+			config:   &Config{IPv6PrefixLength: 64},
+			ip:       "192.168.1.50",
+			expected: "192.168.1.50",
+		},
+		{
+			name:     "Full length prefix keeps address",
+			config:   &Config{IPv4PrefixLength: 32},
+			ip:       "192.168.1.50",
+			expected: "192.168.1.50",
+		},
+		{
+			name:     "Invalid address",
+			config:   &Config{IPv4PrefixLength: 24},
+			ip:       "unknown",
+			expected: "unknown",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := NewRateLimiter(newTestStorage(t), tt.config)
+
+			result := limiter.IPIdentifier(tt.ip)
+			if result != tt.expected {
+				t.Errorf("IPIdentifier(%q) = %v, expected %v", tt.ip, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRateLimiter_CheckLimitIPv6PrefixAggregation(t *testing.T) {
+	config := &Config{
+		IPRequestsPerSecond:    2,
+		IPBlockDurationSeconds: 60,
+		IPv6PrefixLength:       64,
+	}
+
+	memoryStorage := newTestStorage(t)
+	limiter := NewRateLimiter(memoryStorage, config)
+	ctx := context.Background()
+
+	// Endereços diferentes dentro da mesma /64 compartilham o contador
+	addresses := []string{"2001:db8::1", "2001:db8::2", "2001:db8::ffff:3"}
+	for i, address := range addresses {
+		result, err := limiter.CheckLimit(ctx, address, "ip")
+		if err != nil {
+			t.Fatalf("CheckLimit() error = %v", err)
+		}
+
+		expected := i < config.IPRequestsPerSecond
+		if result.Allowed != expected {
+			t.Errorf("CheckLimit(%q) = %v, expected %v", address, result.Allowed, expected)
+		}
+	}
+
+	// Outra /64 possui contador próprio
+	result, err := limiter.CheckLimit(ctx, "2001:db8:0:1::1", "ip")
+	if err != nil {
+		t.Fatalf("CheckLimit() error = %v", err)
+	}
+	if !result.Allowed {
+		t.Errorf("CheckLimit() for a different /64 = %v, expected true", result.Allowed)
+	}
+
+	if exists, _ := memoryStorage.Exists(ctx, "block:ip:2001:db8::/64"); !exists {
+		t.Errorf("expected block key for the aggregated /64 prefix")
+	}
+}
+
+func TestRateLimiter_ResolveToken(t *testing.T) {
+	store := keystore.NewStaticStore("known-key")
+
+	tests := []struct {
+		name          string
+		config        *Config
+		opts          []Option
+		token         string
+		expected      string
+		expectedError error
+	}{
+		{
+			name:     "No key store accepts any token",
+			config:   &Config{},
+			token:    "random-key",
+			expected: "random-key",
+		},
+		{
+			name:     "Known token",
+			config:   &Config{},
+			opts:     []Option{WithKeyStore(store)},
+			token:    "known-key",
+			expected: "known-key",
+		},
+		{
+			name:     "Unknown token falls back to IP limit",
+			config:   &Config{},
+			opts:     []Option{WithKeyStore(store)},
+			token:    "random-key",
+			expected: "",
+		},
+		{
+			name:          "Unknown token rejected",
+			config:        &Config{RejectUnknownTokens: true},
+			opts:          []Option{WithKeyStore(store)},
+			token:         "random-key",
+			expected:      "",
+			expectedError: ErrUnknownToken,
+		},
+		{
+			name:     "Empty token is not validated",
+			config:   &Config{RejectUnknownTokens: true},
+			opts:     []Option{WithKeyStore(store)},
+			token:    "",
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := NewRateLimiter(newTestStorage(t), tt.config, tt.opts...)
+
+			result, _, err := limiter.ResolveToken(context.Background(), tt.token)
+			if !errors.Is(err, tt.expectedError) {
+				t.Fatalf("ResolveToken() error = %v, expected %v", err, tt.expectedError)
+			}
+			if result != tt.expected {
+				t.Errorf("ResolveToken() = %q, expected %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+// stubVerifier aceita apenas tokens iniciados por "jwt-" e usa o restante como tier
+type stubVerifier struct{}
+
+func (stubVerifier) Verify(ctx context.Context, token string) (string, string, error) {
+	if len(token) <= 4 || token[:4] != "jwt-" {
+		return "", "", errors.New("invalid token")
+	}
+	return "sub:" + token, token[4:], nil
+}
+
+func TestRateLimiter_ResolveTokenWithVerifier(t *testing.T) {
+	tests := []struct {
+		name               string
+		opts               []Option
+		token              string
+		expectedIdentifier string
+		expectedTier       string
+	}{
+		{
+			name:               "Verified token",
+			opts:               []Option{WithTokenVerifier(stubVerifier{})},
+			token:              "jwt-gold",
+			expectedIdentifier: "sub:jwt-gold",
+			expectedTier:       "gold",
+		},
+		{
+			name:               "Unverified token falls back to IP limit",
+			opts:               []Option{WithTokenVerifier(stubVerifier{})},
+			token:              "known-key",
+			expectedIdentifier: "",
+		},
+		{
+			name:               "Unverified token accepted by key store",
+			opts:               []Option{WithTokenVerifier(stubVerifier{}), WithKeyStore(keystore.NewStaticStore("known-key"))},
+			token:              "known-key",
+			expectedIdentifier: "known-key",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := NewRateLimiter(newTestStorage(t), &Config{}, tt.opts...)
+
+			identifier, tier, err := limiter.ResolveToken(context.Background(), tt.token)
+			if err != nil {
+				t.Fatalf("ResolveToken() error = %v", err)
+			}
+			if identifier != tt.expectedIdentifier {
+				t.Errorf("ResolveToken() identifier = %q, expected %q", identifier, tt.expectedIdentifier)
+			}
+			if tier != tt.expectedTier {
+				t.Errorf("ResolveToken() tier = %q, expected %q", tier, tt.expectedTier)
+			}
+		})
+	}
+}
+
+func TestRateLimiter_CheckLimitWithTier(t *testing.T) {
+	config := &Config{
+		TokenRequestsPerSecond:    2,
+		TokenBlockDurationSeconds: 60,
+		Tiers: map[string]Tier{
+			"gold": {RequestsPerSecond: 5, BlockDurationSeconds: 10},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		identifier    string
+		tier          string
+		expectedLimit int64
+		expectedRule  string
+	}{
+		{name: "Configured tier", identifier: "sub:gold-user", tier: "gold", expectedLimit: 5, expectedRule: "token:gold"},
+		{name: "Unknown tier uses token limit", identifier: "sub:silver-user", tier: "silver", expectedLimit: 2, expectedRule: "token"},
+		{name: "No tier uses token limit", identifier: "sub:user", tier: "", expectedLimit: 2, expectedRule: "token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := NewRateLimiter(newTestStorage(t), config)
+
+			result, err := limiter.CheckLimitWithTier(context.Background(), tt.identifier, "token", tt.tier)
+			if err != nil {
+				t.Fatalf("CheckLimitWithTier() error = %v", err)
+			}
+			if result.Limit != tt.expectedLimit {
+				t.Errorf("CheckLimitWithTier() limit = %d, expected %d", result.Limit, tt.expectedLimit)
+			}
+			if result.Rule != tt.expectedRule {
+				t.Errorf("CheckLimitWithTier() rule = %q, expected %q", result.Rule, tt.expectedRule)
+			}
+		})
+	}
+}
+
+func TestRateLimiter_TokenHashing(t *testing.T) {
+	config := &Config{
+		TokenRequestsPerSecond:    1,
+		TokenBlockDurationSeconds: 60,
+		TokenHashSecret:           "hash-secret",
+	}
+
+	memoryStorage := newTestStorage(t)
+	limiter := NewRateLimiter(memoryStorage, config)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := limiter.CheckLimit(ctx, "live-api-key", "token"); err != nil {
+			t.Fatalf("CheckLimit() error = %v", err)
+		}
+	}
+
+	key, blockKey := limiter.StorageKeys("live-api-key", "token")
+	if exists, _ := memoryStorage.Exists(ctx, key); !exists {
+		t.Errorf("expected counter key %q", key)
+	}
+	if exists, _ := memoryStorage.Exists(ctx, blockKey); !exists {
+		t.Errorf("expected block key %q", blockKey)
+	}
+	storedKeys, _, err := memoryStorage.Scan(ctx, 0, "*", 100)
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	for _, storedKey := range storedKeys {
+		if strings.Contains(storedKey, "live-api-key") {
+			t.Errorf("storage key %q contains the raw token", storedKey)
+		}
+	}
+
+	// Outro segredo gera outro identificador
+	other := NewRateLimiter(memoryStorage, &Config{TokenHashSecret: "other-secret"})
+	if other.KeyIdentifier("live-api-key", "token") == limiter.KeyIdentifier("live-api-key", "token") {
+		t.Errorf("KeyIdentifier() should depend on the hash secret")
+	}
+
+	// IPs não são transformados em hash
+	if result := limiter.KeyIdentifier("192.168.1.1", "ip"); result != "192.168.1.1" {
+		t.Errorf("KeyIdentifier() for IP = %q, expected %q", result, "192.168.1.1")
+	}
+}
+
+func TestRateLimiter_StorageKeys(t *testing.T) {
+	tests := []struct {
+		name          string
+		config        *Config
+		identifier    string
+		limitType     string
+		expectedKey   string
+		expectedBlock string
+	}{
+		{
+			name:          "No namespace",
+			config:        &Config{},
+			identifier:    "192.168.1.1",
+			limitType:     "ip",
+			expectedKey:   "rate_limit:ip:192.168.1.1",
+			expectedBlock: "block:ip:192.168.1.1",
+		},
+		{
+			name:          "Application namespace",
+			config:        &Config{KeyNamespace: "checkout"},
+			identifier:    "192.168.1.1",
+			limitType:     "ip",
+			expectedKey:   "checkout:rate_limit:ip:192.168.1.1",
+			expectedBlock: "checkout:block:ip:192.168.1.1",
+		},
+		{
+			name:          "Nested namespace with token",
+			config:        &Config{KeyNamespace: "prod:checkout"},
+			identifier:    "abc123",
+			limitType:     "token",
+			expectedKey:   "prod:checkout:rate_limit:token:abc123",
+			expectedBlock: "prod:checkout:block:token:abc123",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := NewRateLimiter(newTestStorage(t), tt.config)
+
+			key, blockKey := limiter.StorageKeys(tt.identifier, tt.limitType)
+			if key != tt.expectedKey {
+				t.Errorf("StorageKeys() key = %q, expected %q", key, tt.expectedKey)
+			}
+			if blockKey != tt.expectedBlock {
+				t.Errorf("StorageKeys() block key = %q, expected %q", blockKey, tt.expectedBlock)
+			}
+		})
+	}
+}
+
+func TestRateLimiter_LegacyKeyFallback(t *testing.T) {
+	tests := []struct {
+		name            string
+		fallback        bool
+		expectedAllowed bool
+	}{
+		{name: "Legacy block honored during migration", fallback: true, expectedAllowed: false},
+		{name: "Legacy block ignored without fallback", fallback: false, expectedAllowed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{
+				TokenRequestsPerSecond:    10,
+				TokenBlockDurationSeconds: 60,
+				KeyNamespace:              "checkout",
+				TokenHashSecret:           "hash-secret",
+				LegacyKeyFallback:         tt.fallback,
+			}
+
+			memoryStorage := newTestStorage(t)
+			// Bloqueio criado antes do namespace e do hash de tokens
+			memoryStorage.Set(context.Background(), "block:token:abc123", 1, time.Minute)
+			limiter := NewRateLimiter(memoryStorage, config)
+
+			result, err := limiter.CheckLimit(context.Background(), "abc123", "token")
+			if err != nil {
+				t.Fatalf("CheckLimit() error = %v", err)
+			}
+			if result.Allowed != tt.expectedAllowed {
+				t.Errorf("CheckLimit() = %v, expected %v", result.Allowed, tt.expectedAllowed)
+			}
+		})
+	}
+}
+
+func TestRateLimiter_CheckLimitBlockedRetryAfter(t *testing.T) {
+	config := &Config{
+		IPRequestsPerSecond:    1,
+		IPBlockDurationSeconds: 60,
+	}
+
+	memoryStorage := newTestStorage(t)
+	limiter := NewRateLimiter(memoryStorage, config)
+	ctx := context.Background()
+
+	// A requisição que excede o limite cria o bloqueio com a duração completa
+	for i := 0; i < 2; i++ {
+		if _, err := limiter.CheckLimit(ctx, "10.0.0.1", "ip"); err != nil {
+			t.Fatalf("CheckLimit() error = %v", err)
+		}
+	}
+
+	_, blockKey := limiter.StorageKeys("10.0.0.1", "ip")
+	if ttl, _ := memoryStorage.TTL(ctx, blockKey); ttl <= 59*time.Second || ttl > 60*time.Second {
+		t.Fatalf("block TTL = %v, expected %v", ttl, 60*time.Second)
+	}
+
+	// Requisições seguintes informam o tempo restante lido do storage
+	memoryStorage.Set(ctx, blockKey, 1, 15*time.Second)
+	result, err := limiter.CheckLimit(ctx, "10.0.0.1", "ip")
+	if err != nil {
+		t.Fatalf("CheckLimit() error = %v", err)
+	}
+	if result.Allowed {
+		t.Fatalf("CheckLimit() = %v, expected blocked", result.Allowed)
+	}
+	if result.RetryAfter <= 14*time.Second || result.RetryAfter > 15*time.Second {
+		t.Errorf("RetryAfter = %v, expected %v", result.RetryAfter, 15*time.Second)
+	}
+	if until := time.Until(result.ResetTime); until > 15*time.Second || until < 14*time.Second {
+		t.Errorf("ResetTime in %v, expected about %v", until, 15*time.Second)
+	}
+
+	// Bloqueios sem expiração usam a duração configurada
+	memoryStorage.Set(ctx, blockKey, 1, 0)
+	result, err = limiter.CheckLimit(ctx, "10.0.0.1", "ip")
+	if err != nil {
+		t.Fatalf("CheckLimit() error = %v", err)
+	}
+	if result.RetryAfter != 60*time.Second {
+		t.Errorf("RetryAfter = %v, expected %v", result.RetryAfter, 60*time.Second)
+	}
+}
+
+func TestNew_Options(t *testing.T) {
+	ctx := context.Background()
+
+	rl := New(newTestStorage(t))
+	if !reflect.DeepEqual(rl.config, DefaultConfig()) {
+		t.Errorf("New() config = %+v, expected %+v", *rl.config, *DefaultConfig())
+	}
+
+	rl = New(newTestStorage(t),
+		WithIPLimit(2, 30),
+		WithTokenLimit(3, 60),
+		WithTier("pro", Tier{RequestsPerSecond: 5, BlockDurationSeconds: 10}),
+		WithTokenHeaders("X-API-Key"),
+		WithKeyNamespace("checkout"),
+	)
+
+	for i := 0; i < 2; i++ {
+		if result, _ := rl.CheckLimit(ctx, "192.168.1.1", "ip"); !result.Allowed {
+			t.Fatalf("request %d should be allowed", i+1)
+		}
+	}
+	result, err := rl.CheckLimit(ctx, "192.168.1.1", "ip")
+	if err != nil {
+		t.Fatalf("CheckLimit() error = %v", err)
+	}
+	if result.Allowed || result.RetryAfter != 30*time.Second {
+		t.Errorf("third IP request = allowed %v, retry after %v; expected blocked for 30s", result.Allowed, result.RetryAfter)
+	}
+
+	if result, _ := rl.CheckLimit(ctx, "token-abc", "token"); result.Limit != 3 {
+		t.Errorf("token Limit = %d, expected 3", result.Limit)
+	}
+	if result, _ := rl.CheckLimitWithTier(ctx, "token-pro", "token", "pro"); result.Limit != 5 {
+		t.Errorf("pro tier Limit = %d, expected 5", result.Limit)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-API-Key", "abc123")
+	if token := rl.ExtractTokenFromHeader(req); token != "abc123" {
+		t.Errorf("ExtractTokenFromHeader() = %q, expected %q", token, "abc123")
+	}
+
+	if key, _ := rl.StorageKeys("192.168.1.1", "ip"); !strings.HasPrefix(key, "checkout:") {
+		t.Errorf("StorageKeys() = %q, expected the checkout namespace", key)
+	}
+}
+
+func TestNew_WithConfig(t *testing.T) {
+	rl := New(newTestStorage(t),
+		WithConfig(Config{IPRequestsPerSecond: 1, IPBlockDurationSeconds: 5}),
+		WithTokenLimit(7, 5),
+	)
+
+	if rl.config.IPRequestsPerSecond != 1 || rl.config.TokenRequestsPerSecond != 7 {
+		t.Errorf("config = %+v, expected IP limit 1 and token limit 7", *rl.config)
+	}
+}
+
+func TestNew_WithConfigTokenSources(t *testing.T) {
+	rl := New(newTestStorage(t),
+		WithConfig(Config{TokenHeaders: []string{"X-API-Key"}, TokenQueryParam: "api_key"}),
+	)
+
+	tests := []struct {
+		name     string
+		header   string
+		query    string
+		expected string
+	}{
+		{name: "Configured header", header: "X-API-Key", expected: "header-token"},
+		{name: "Default header is not read", header: "API_KEY", expected: ""},
+		{name: "Configured query parameter", query: "api_key=query-token", expected: "query-token"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/?"+tt.query, nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, "header-token")
+			}
+			if result := rl.ExtractTokenFromHeader(req); result != tt.expected {
+				t.Errorf("ExtractTokenFromHeader() = %q, expected %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCheckLimit_Metrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	// Com intervalo mínimo, cada coleta conta os bloqueios no storage
+	m := metrics.New(registry, metrics.WithBlockCountInterval(time.Nanosecond))
+	rl := New(newTestStorage(t), WithIPLimit(1, 60), WithMetrics(m))
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if _, err := rl.CheckLimit(ctx, "192.168.1.1", "ip"); err != nil {
+			t.Fatalf("CheckLimit() error = %v", err)
+		}
+	}
+
+	expected := `
+# HELP rate_limiter_active_blocks Active blocks in storage by limit type.
+# TYPE rate_limiter_active_blocks gauge
+rate_limiter_active_blocks{limit_type="ip"} 1
+rate_limiter_active_blocks{limit_type="token"} 0
+# HELP rate_limiter_decisions_total Rate limit decisions by limit type, rule and result (allowed or denied).
+# TYPE rate_limiter_decisions_total counter
+rate_limiter_decisions_total{decision="allowed",limit_type="ip",rule="ip"} 1
+rate_limiter_decisions_total{decision="denied",limit_type="ip",rule="ip"} 2
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expected), "rate_limiter_decisions_total", "rate_limiter_active_blocks"); err != nil {
+		t.Errorf("unexpected metrics: %v", err)
+	}
+	if count, err := testutil.GatherAndCount(registry, "rate_limiter_check_duration_seconds"); err != nil || count != 1 {
+		t.Errorf("check duration series = %d (err: %v), expected 1", count, err)
+	}
+
+	// Os bloqueios ativos são lidos do storage: renovar um bloqueio não o
+	// conta duas vezes, e o desbloqueio é refletido
+	if err := rl.Block(ctx, "192.168.1.1", "ip", time.Minute); err != nil {
+		t.Fatalf("Block() error = %v", err)
+	}
+	if err := rl.Block(ctx, "api-key", "token", time.Minute); err != nil {
+		t.Fatalf("Block() error = %v", err)
+	}
+	if err := rl.Unblock(ctx, "192.168.1.1", "ip"); err != nil {
+		t.Fatalf("Unblock() error = %v", err)
+	}
+	expected = `
+# HELP rate_limiter_active_blocks Active blocks in storage by limit type.
+# TYPE rate_limiter_active_blocks gauge
+rate_limiter_active_blocks{limit_type="ip"} 0
+rate_limiter_active_blocks{limit_type="token"} 1
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expected), "rate_limiter_active_blocks"); err != nil {
+		t.Errorf("unexpected active blocks after Unblock(): %v", err)
+	}
+}
+
+func TestCheckLimit_Tracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	rl := New(storage.NewTracedStorage(newTestStorage(t), provider),
+		WithIPLimit(1, 60),
+		WithTracerProvider(provider),
+	)
+
+	ctx := context.Background()
+	rl.CheckLimit(ctx, "192.168.1.1", "ip")
+	exporter.Reset()
+	rl.CheckLimit(ctx, "192.168.1.1", "ip")
+
+	spans := exporter.GetSpans()
+	var check tracetest.SpanStub
+	var storageSpans []string
+	for _, span := range spans {
+		if span.Name == "rate_limiter.check_limit" {
+			check = span
+		} else {
+			storageSpans = append(storageSpans, span.Name)
+		}
+	}
+
+	if check.Name == "" {
+		t.Fatalf("missing rate_limiter.check_limit span, got %d spans", len(spans))
+	}
+	attributes := make(map[attribute.Key]attribute.Value)
+	for _, kv := range check.Attributes {
+		attributes[kv.Key] = kv.Value
+	}
+	if attributes["rate_limiter.limit_type"].AsString() != "ip" {
+		t.Errorf("limit_type = %q, expected %q", attributes["rate_limiter.limit_type"].AsString(), "ip")
+	}
+	if attributes["rate_limiter.allowed"].AsBool() {
+		t.Errorf("allowed = true, expected false for the second request")
+	}
+	if attributes["rate_limiter.remaining"].AsInt64() != 0 {
+		t.Errorf("remaining = %d, expected 0", attributes["rate_limiter.remaining"].AsInt64())
+	}
+
+	expectedStorage := []string{"rate_limiter.storage.exists", "rate_limiter.storage.increment", "rate_limiter.storage.set"}
+	if !reflect.DeepEqual(storageSpans, expectedStorage) {
+		t.Errorf("storage spans = %v, expected %v", storageSpans, expectedStorage)
+	}
+	for _, span := range spans {
+		if span.Name != check.Name && span.Parent.SpanID() != check.SpanContext.SpanID() {
+			t.Errorf("span %s is not a child of rate_limiter.check_limit", span.Name)
+		}
+	}
+}
+
+func TestCheckLimit_Logging(t *testing.T) {
+	tests := []struct {
+		name             string
+		sampleRate       float64
+		level            slog.Level
+		expectedMessages []string
+	}{
+		{
+			name:             "Denials and blocks only",
+			sampleRate:       0,
+			level:            slog.LevelInfo,
+			expectedMessages: []string{"rate limit block created", "rate limit request denied"},
+		},
+		{
+			name:             "All allowed requests sampled",
+			sampleRate:       1,
+			level:            slog.LevelInfo,
+			expectedMessages: []string{"rate limit request allowed", "rate limit block created", "rate limit request denied"},
+		},
+		{
+			name:             "Warn level keeps only blocks",
+			sampleRate:       1,
+			level:            slog.LevelWarn,
+			expectedMessages: []string{"rate limit block created"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output bytes.Buffer
+			logger := slog.New(slog.NewJSONHandler(&output, &slog.HandlerOptions{Level: tt.level}))
+			rl := New(newTestStorage(t),
+				WithTokenLimit(1, 60),
+				WithLogger(logger),
+				WithAllowedLogSampleRate(tt.sampleRate),
+			)
+
+			ctx := context.Background()
+			for i := 0; i < 3; i++ {
+				if _, err := rl.CheckLimit(ctx, "secret-api-key", "token"); err != nil {
+					t.Fatalf("CheckLimit() error = %v", err)
+				}
+			}
+
+			if strings.Contains(output.String(), "secret-api-key") {
+				t.Errorf("log output contains the raw token: %s", output.String())
+			}
+
+			var messages []string
+			for _, line := range strings.Split(strings.TrimSpace(output.String()), "\n") {
+				if line == "" {
+					continue
+				}
+				var entry map[string]interface{}
+				if err := json.Unmarshal([]byte(line), &entry); err != nil {
+					t.Fatalf("invalid JSON log line %q: %v", line, err)
+				}
+				messages = append(messages, entry["msg"].(string))
+
+				if entry["rule"] != "token" || entry["limit_type"] != "token" || entry["reset_time"] == nil {
+					t.Errorf("log entry missing decision fields: %v", entry)
+				}
+				if entry["msg"] == "rate limit block created" && entry["count"] != float64(2) {
+					t.Errorf("block log count = %v, expected 2", entry["count"])
+				}
+			}
+
+			if !reflect.DeepEqual(messages, tt.expectedMessages) {
+				t.Errorf("messages = %v, expected %v", messages, tt.expectedMessages)
+			}
+		})
+	}
+}
+
+func TestRedactedIdentifier(t *testing.T) {
+	tests := []struct {
+		name       string
+		identifier string
+		limitType  string
+		opts       []Option
+		expected   string
+	}{
+		{name: "IP hashed by default", identifier: "192.168.1.10", limitType: "ip", expected: "sha256:"},
+		{name: "IP hashed with secret", identifier: "192.168.1.10", limitType: "ip", opts: []Option{WithTokenHashSecret("hash-secret")}, expected: "hmac:"},
+		{name: "IP in plain text", identifier: "192.168.1.10", limitType: "ip", opts: []Option{WithPlainIPLogging(true)}, expected: "192.168.1.10"},
+		{name: "Aggregated IP in plain text", identifier: "192.168.1.10", limitType: "ip", opts: []Option{WithPlainIPLogging(true), WithIPPrefixLengths(24, 64)}, expected: "192.168.1.0/24"},
+		{name: "Token without secret", identifier: "secret-api-key", limitType: "token", expected: "sha256:"},
+		{name: "Token with secret", identifier: "secret-api-key", limitType: "token", opts: []Option{WithTokenHashSecret("hash-secret")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rl := New(newTestStorage(t), tt.opts...)
+			redacted := rl.RedactedIdentifier(tt.identifier, tt.limitType)
+
+			switch {
+			case tt.expected == "":
+				// Tokens com segredo aparecem como nas chaves do storage
+				if expected := rl.KeyIdentifier(tt.identifier, tt.limitType); redacted != expected {
+					t.Errorf("RedactedIdentifier() = %q, expected the key identifier %q", redacted, expected)
+				}
+			case strings.HasSuffix(tt.expected, ":"):
+				if !strings.HasPrefix(redacted, tt.expected) || len(redacted) != len(tt.expected)+16 {
+					t.Errorf("RedactedIdentifier() = %q, expected a %s hash prefix", redacted, tt.expected)
+				}
+			case redacted != tt.expected:
+				t.Errorf("RedactedIdentifier() = %q, expected %q", redacted, tt.expected)
+			}
+
+			// O identificador das chaves leva ao mesmo valor redigido
+			if fromKey := rl.RedactedKeyIdentifier(rl.KeyIdentifier(tt.identifier, tt.limitType), tt.limitType); fromKey != redacted {
+				t.Errorf("RedactedKeyIdentifier() = %q, expected %q", fromKey, redacted)
+			}
+		})
+	}
+}
+
+func TestCheckLimit_AuditBlockEvent(t *testing.T) {
+	var output bytes.Buffer
+	emitter := audit.NewEmitter(audit.NewWriterSink(&output))
+	rl := New(newTestStorage(t), WithTokenLimit(1, 60), WithAuditEmitter(emitter))
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		rl.CheckLimit(ctx, "secret-api-key", "token")
+	}
+
+	closeCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if err := emitter.Close(closeCtx); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("got %d audit events, expected 1 (only the block creation): %s", len(lines), output.String())
+	}
+
+	var event audit.Event
+	if err := json.Unmarshal([]byte(lines[0]), &event); err != nil {
+		t.Fatalf("invalid audit event: %v", err)
+	}
+	if event.Type != audit.BlockEvent || event.Source != audit.SourceLimiter {
+		t.Errorf("event = %s from %s, expected block from limiter", event.Type, event.Source)
+	}
+	if event.Duration != time.Minute || event.ExpiresAt == nil {
+		t.Errorf("event duration = %v, expires at %v; expected 1m with expiration", event.Duration, event.ExpiresAt)
+	}
+	if event.Identifier != rl.RedactedIdentifier("secret-api-key", "token") || strings.Contains(lines[0], "secret-api-key") {
+		t.Errorf("event identifier = %q, expected the redacted token", event.Identifier)
+	}
+}
+
+func TestRateLimiter_ManageLimits(t *testing.T) {
+	ctx := context.Background()
+	rl := New(newTestStorage(t), WithIPLimit(3, 60))
+
+	for i := 0; i < 2; i++ {
+		rl.CheckLimit(ctx, "192.168.1.1", "ip")
+	}
+
+	status, err := rl.Inspect(ctx, "192.168.1.1", "ip", "")
+	if err != nil {
+		t.Fatalf("Inspect() error = %v", err)
+	}
+	if status.Count != 2 || status.Remaining != 1 || status.Blocked {
+		t.Errorf("Inspect() = %+v, expected count 2, remaining 1 and not blocked", status)
+	}
+
+	if err := rl.ResetCounter(ctx, "192.168.1.1", "ip"); err != nil {
+		t.Fatalf("ResetCounter() error = %v", err)
+	}
+	status, _ = rl.Inspect(ctx, "192.168.1.1", "ip", "")
+	if status.Count != 0 || status.Remaining != 3 {
+		t.Errorf("after ResetCounter() = %+v, expected count 0 and remaining 3", status)
+	}
+
+	if err := rl.Block(ctx, "192.168.1.1", "ip", 90*time.Second); err != nil {
+		t.Fatalf("Block() error = %v", err)
+	}
+	status, _ = rl.Inspect(ctx, "192.168.1.1", "ip", "")
+	if !status.Blocked || status.BlockTTL.Round(time.Second) != 90*time.Second || status.Remaining != 0 {
+		t.Errorf("after Block() = %+v, expected blocked for 90s", status)
+	}
+	if result, _ := rl.CheckLimit(ctx, "192.168.1.1", "ip"); result.Allowed {
+		t.Error("CheckLimit() should deny a manually blocked identifier")
+	}
+
+	if err := rl.Unblock(ctx, "192.168.1.1", "ip"); err != nil {
+		t.Fatalf("Unblock() error = %v", err)
+	}
+	if result, _ := rl.CheckLimit(ctx, "192.168.1.1", "ip"); !result.Allowed {
+		t.Error("CheckLimit() should allow an unblocked identifier")
+	}
+
+	if err := rl.Block(ctx, "192.168.1.1", "ip", 0); err == nil {
+		t.Error("Block() with zero duration should return an error")
+	}
+	if _, err := rl.Inspect(ctx, "user-1", "user", ""); err == nil {
+		t.Error("Inspect() with invalid limit type should return an error")
+	}
+
+	// IPs informados com porta ou mapeados em IPv6 usam as chaves de CheckLimit
+	for _, identifier := range []string{"::ffff:10.0.0.1", "10.0.0.1:8080", "[::ffff:10.0.0.1]:80"} {
+		if err := rl.Block(ctx, identifier, "ip", time.Minute); err != nil {
+			t.Fatalf("Block(%q) error = %v", identifier, err)
+		}
+		if result, _ := rl.CheckLimit(ctx, "10.0.0.1", "ip"); result.Allowed {
+			t.Errorf("CheckLimit() should deny 10.0.0.1 after Block(%q)", identifier)
+		}
+		if status, _ := rl.Inspect(ctx, identifier, "ip", ""); !status.Blocked {
+			t.Errorf("Inspect(%q) = %+v, expected blocked", identifier, status)
+		}
+		if err := rl.Unblock(ctx, identifier, "ip"); err != nil {
+			t.Fatalf("Unblock(%q) error = %v", identifier, err)
+		}
+		rl.ResetCounter(ctx, identifier, "ip")
+		if result, _ := rl.CheckLimit(ctx, "10.0.0.1", "ip"); !result.Allowed {
+			t.Errorf("CheckLimit() should allow 10.0.0.1 after Unblock(%q)", identifier)
+		}
+		rl.ResetCounter(ctx, identifier, "ip")
+	}
+}
+
+func TestRateLimiter_ScanBlocks(t *testing.T) {
+	ctx := context.Background()
+	memoryStorage := newTestStorage(t)
+	rl := New(memoryStorage, WithKeyNamespace("checkout"), WithTokenHashSecret("hash-secret"))
+
+	rl.Block(ctx, "192.168.1.1", "ip", time.Minute)
+	rl.Block(ctx, "api-key", "token", 2*time.Minute)
+	memoryStorage.Set(ctx, "block:ip:10.0.0.1", 1, time.Minute)
+	memoryStorage.Set(ctx, "other:block:ip:10.0.0.2", 1, time.Minute)
+
+	blocks, next, err := rl.ScanBlocks(ctx, 0, 100)
+	if err != nil {
+		t.Fatalf("ScanBlocks() error = %v", err)
+	}
+	if next != 0 || len(blocks) != 2 {
+		t.Fatalf("ScanBlocks() = %+v (next %d), expected the 2 blocks in the namespace", blocks, next)
+	}
+
+	found := make(map[string]BlockEntry)
+	for _, block := range blocks {
+		found[block.LimitType] = block
+	}
+	if found["ip"].KeyIdentifier != "192.168.1.1" || found["ip"].TTL.Round(time.Second) != time.Minute {
+		t.Errorf("ip block = %+v, expected 192.168.1.1 for 1m", found["ip"])
+	}
+	if found["token"].KeyIdentifier != rl.KeyIdentifier("api-key", "token") || found["token"].TTL.Round(time.Second) != 2*time.Minute {
+		t.Errorf("token block = %+v, expected the hashed token for 2m", found["token"])
+	}
+}
+
+func TestCheckLimit_HeavyHitters(t *testing.T) {
+	tracker := heavyhitters.NewMemoryTracker(5)
+	rl := New(newTestStorage(t), WithTokenLimit(2, 60), WithTokenHashSecret("hash-secret"), WithHeavyHitters(tracker))
+
+	ctx := context.Background()
+	for i := 0; i < 4; i++ {
+		rl.CheckLimit(ctx, "api-key", "token")
+	}
+
+	consumers, err := rl.HeavyHitters().Top(ctx, "token", time.Minute, 10)
+	if err != nil {
+		t.Fatalf("Top() error = %v", err)
+	}
+	expected := heavyhitters.Consumer{Identifier: rl.KeyIdentifier("api-key", "token"), Requests: 4, Denied: 2, PeakRate: 3}
+	if len(consumers) != 1 || consumers[0] != expected {
+		t.Errorf("Top() = %+v, expected %+v", consumers, expected)
+	}
+}
+
+func TestRateLimiter_ManageLimitsByKey(t *testing.T) {
+	ctx := context.Background()
+	rl := New(newTestStorage(t), WithTokenLimit(2, 60), WithTokenHashSecret("hash-secret"))
+
+	for i := 0; i < 3; i++ {
+		rl.CheckLimit(ctx, "api-key", "token")
+	}
+
+	blocks, _, err := rl.ScanBlocks(ctx, 0, 100)
+	if err != nil || len(blocks) != 1 {
+		t.Fatalf("ScanBlocks() = %+v, %v, expected the token block", blocks, err)
+	}
+	keyIdentifier := blocks[0].KeyIdentifier
+
+	status, err := rl.InspectKey(ctx, keyIdentifier, "token", "")
+	if err != nil {
+		t.Fatalf("InspectKey() error = %v", err)
+	}
+	if !status.Blocked || status.KeyIdentifier != rl.KeyIdentifier("api-key", "token") {
+		t.Errorf("InspectKey() = %+v, expected the blocked hashed token", status)
+	}
+
+	if err := rl.UnblockKey(ctx, keyIdentifier, "token"); err != nil {
+		t.Fatalf("UnblockKey() error = %v", err)
+	}
+	if err := rl.ResetCounterKey(ctx, keyIdentifier, "token"); err != nil {
+		t.Fatalf("ResetCounterKey() error = %v", err)
+	}
+	if result, _ := rl.CheckLimit(ctx, "api-key", "token"); !result.Allowed {
+		t.Error("CheckLimit() should allow the token after UnblockKey() and ResetCounterKey()")
+	}
+
+	if err := rl.BlockKey(ctx, keyIdentifier, "token", time.Minute); err != nil {
+		t.Fatalf("BlockKey() error = %v", err)
+	}
+	if result, _ := rl.CheckLimit(ctx, "api-key", "token"); result.Allowed {
+		t.Error("CheckLimit() should deny the token after BlockKey()")
+	}
+}
+
+func TestRateLimiter_Decisions(t *testing.T) {
+	ctx := context.Background()
+	rl := New(newTestStorage(t), WithIPLimit(2, 60), WithTokenLimit(1, 60))
+
+	for i := 0; i < 3; i++ {
+		rl.CheckLimit(ctx, "192.168.1.1", "ip")
+	}
+	rl.CheckLimit(ctx, "api-key", "token")
+	rl.CheckLimit(ctx, "api-key", "user")
+
+	expected := map[string]DecisionCount{
+		"ip":    {Allowed: 2, Denied: 1},
+		"token": {Allowed: 1},
+	}
+	if decisions := rl.Decisions(); !reflect.DeepEqual(decisions, expected) {
+		t.Errorf("Decisions() = %+v, expected %+v", decisions, expected)
+	}
+}
+
+func TestCheckLimit_ShadowMode(t *testing.T) {
+	ctx := context.Background()
+	memoryStorage := newTestStorage(t)
+	registry := prometheus.NewRegistry()
+	var output bytes.Buffer
+	rl := New(memoryStorage,
+		WithIPLimit(5, 60),
+		WithTokenLimit(1, 60),
+		WithShadowRule("ip", Tier{RequestsPerSecond: 1, BlockDurationSeconds: 30}),
+		WithMetrics(metrics.New(registry)),
+		WithLogger(slog.New(slog.NewJSONHandler(&output, nil))),
+	)
+
+	// O limite atual (5 req/s) continua aplicado; o candidato (1 req/s)
+	// apenas marca as requisições que negaria
+	expected := []struct {
+		allowed          bool
+		remaining        int64
+		shadowDenied     bool
+		shadowRetryAfter time.Duration
+	}{
+		{allowed: true, remaining: 4},
+		{allowed: true, remaining: 3, shadowDenied: true, shadowRetryAfter: 30 * time.Second},
+		{allowed: true, remaining: 2, shadowDenied: true, shadowRetryAfter: 30 * time.Second},
+		{allowed: true, remaining: 1, shadowDenied: true, shadowRetryAfter: 30 * time.Second},
+		{allowed: true, remaining: 0, shadowDenied: true, shadowRetryAfter: 30 * time.Second},
+		{allowed: false, remaining: 0, shadowDenied: true, shadowRetryAfter: 30 * time.Second},
+	}
+	for i, want := range expected {
+		result, err := rl.CheckLimit(ctx, "192.168.1.1", "ip")
+		if err != nil {
+			t.Fatalf("CheckLimit() error = %v", err)
+		}
+		if result.Allowed != want.allowed || result.Remaining != want.remaining {
+			t.Errorf("request %d: Allowed = %v, Remaining = %d, expected %v and %d", i+1, result.Allowed, result.Remaining, want.allowed, want.remaining)
+		}
+		if !result.Shadow || result.ShadowLimit != 1 {
+			t.Errorf("request %d: Shadow = %v, ShadowLimit = %d, expected true and 1", i+1, result.Shadow, result.ShadowLimit)
+		}
+		if result.ShadowDenied != want.shadowDenied || result.ShadowRetryAfter.Round(time.Second) != want.shadowRetryAfter {
+			t.Errorf("request %d: ShadowDenied = %v, ShadowRetryAfter = %v, expected %v and %v", i+1, result.ShadowDenied, result.ShadowRetryAfter, want.shadowDenied, want.shadowRetryAfter)
+		}
+	}
+
+	// O bloqueio do candidato é apenas simulado, em uma chave própria
+	if exists, _ := memoryStorage.Exists(ctx, "shadow_block:ip:192.168.1.1"); !exists {
+		t.Errorf("shadow block key was not created")
+	}
+
+	// Regras sem limite candidato não são afetadas
+	rl.CheckLimit(ctx, "api-key", "token")
+	if result, _ := rl.CheckLimit(ctx, "api-key", "token"); result.Allowed || result.Shadow {
+		t.Errorf("token result = %+v, expected enforced denial without shadow", result)
+	}
+
+	expectedMetrics := `
+# HELP rate_limiter_decisions_total Rate limit decisions by limit type, rule and result (allowed or denied).
+# TYPE rate_limiter_decisions_total counter
+rate_limiter_decisions_total{decision="allowed",limit_type="ip",rule="ip"} 5
+rate_limiter_decisions_total{decision="denied",limit_type="ip",rule="ip"} 1
+rate_limiter_decisions_total{decision="allowed",limit_type="token",rule="token"} 1
+rate_limiter_decisions_total{decision="denied",limit_type="token",rule="token"} 1
+# HELP rate_limiter_shadow_denials_total Requests that a candidate limit in shadow mode would have denied.
+# TYPE rate_limiter_shadow_denials_total counter
+rate_limiter_shadow_denials_total{limit_type="ip",rule="ip"} 5
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expectedMetrics), "rate_limiter_decisions_total", "rate_limiter_shadow_denials_total"); err != nil {
+		t.Errorf("unexpected metrics: %v", err)
+	}
+
+	var messages []string
+	for _, line := range strings.Split(strings.TrimSpace(output.String()), "\n") {
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("invalid JSON log line %q: %v", line, err)
+		}
+		if entry["limit_type"] == "ip" {
+			messages = append(messages, entry["msg"].(string))
+			if entry["shadow_denied"] != true || entry["shadow_limit"] != float64(1) {
+				t.Errorf("shadow log entry missing shadow fields: %v", entry)
+			}
+		}
+	}
+	expectedMessages := []string{
+		"rate limit shadow block created",
+		"rate limit request denied in shadow mode",
+		"rate limit request denied in shadow mode",
+		"rate limit request denied in shadow mode",
+		"rate limit block created",
+	}
+	if !reflect.DeepEqual(messages, expectedMessages) {
+		t.Errorf("messages = %v, expected %v", messages, expectedMessages)
+	}
+
+	// O desbloqueio remove também o bloqueio simulado
+	if err := rl.Unblock(ctx, "192.168.1.1", "ip"); err != nil {
+		t.Fatalf("Unblock() error = %v", err)
+	}
+	if exists, _ := memoryStorage.Exists(ctx, "shadow_block:ip:192.168.1.1"); exists {
+		t.Errorf("shadow block key was not removed by Unblock()")
+	}
+}
@@ -0,0 +1,147 @@
+package limiter
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+)
+
+// WithLogger registra as decisões do limiter no logger informado: criação de
+// bloqueios (Warn), requisições negadas (Info) e, por amostragem, requisições
+// permitidas (Info, ver WithAllowedLogSampleRate). Bloqueios e negações
+// simulados por limites candidatos em shadow mode são registrados nos mesmos
+// níveis, com shadow_denied=true.
+// Sem logger, nada é registrado.
+func WithLogger(logger *slog.Logger) Option {
+	return func(rl *RateLimiter) {
+		rl.logger = logger
+	}
+}
+
+// WithPlainIPLogging registra os IPs (agregados pelo prefixo configurado) em
+// texto puro nos logs e na auditoria. Por padrão, eles são representados por
+// hash, como os tokens (ver RedactedIdentifier).
+func WithPlainIPLogging(enabled bool) Option {
+	return func(rl *RateLimiter) {
+		rl.plainIPLogging = enabled
+	}
+}
+
+// WithAllowedLogSampleRate define a fração das requisições permitidas que é
+// registrada (0 desativa, 1 registra todas). Negações e bloqueios são sempre
+// registrados.
+func WithAllowedLogSampleRate(rate float64) Option {
+	return func(rl *RateLimiter) {
+		rl.allowedLogSampleRate = rate
+	}
+}
+
+// decisionLog descreve uma decisão do limiter para o log
+type decisionLog struct {
+	identifier string
+	limitType  string
+	result     *LimitResult
+	// count é o valor do contador na janela atual; -1 quando a requisição foi
+	// negada por um bloqueio existente, sem incrementar o contador
+	count        int64
+	blockCreated bool
+	// shadowBlockCreated indica que o limite candidato em shadow mode criou
+	// um bloqueio simulado
+	shadowBlockCreated bool
+}
+
+// logDecision registra a decisão conforme o tipo e a amostragem configurada
+func (rl *RateLimiter) logDecision(ctx context.Context, decision decisionLog) {
+	if rl.logger == nil {
+		return
+	}
+
+	var level slog.Level
+	var message string
+	switch {
+	case decision.blockCreated:
+		level, message = slog.LevelWarn, "rate limit block created"
+	case !decision.result.Allowed:
+		level, message = slog.LevelInfo, "rate limit request denied"
+	case decision.shadowBlockCreated:
+		level, message = slog.LevelWarn, "rate limit shadow block created"
+	case decision.result.ShadowDenied:
+		level, message = slog.LevelInfo, "rate limit request denied in shadow mode"
+	default:
+		if rl.allowedLogSampleRate <= 0 || rand.Float64() >= rl.allowedLogSampleRate {
+			return
+		}
+		level, message = slog.LevelInfo, "rate limit request allowed"
+	}
+
+	if !rl.logger.Enabled(ctx, level) {
+		return
+	}
+
+	result := decision.result
+	attrs := []slog.Attr{
+		slog.String("identifier", rl.RedactedIdentifier(decision.identifier, decision.limitType)),
+		slog.String("limit_type", decision.limitType),
+		slog.String("rule", result.Rule),
+		slog.Int64("limit", result.Limit),
+		slog.Int64("remaining", result.Remaining),
+		slog.Time("reset_time", result.ResetTime),
+	}
+	if decision.count >= 0 {
+		attrs = append(attrs, slog.Int64("count", decision.count))
+	}
+	if !result.Allowed {
+		attrs = append(attrs, slog.Duration("retry_after", result.RetryAfter.Round(time.Millisecond)))
+	}
+	if result.Shadow {
+		attrs = append(attrs, slog.Int64("shadow_limit", result.ShadowLimit), slog.Bool("shadow_denied", result.ShadowDenied))
+		if result.ShadowDenied {
+			attrs = append(attrs, slog.Duration("shadow_retry_after", result.ShadowRetryAfter.Round(time.Millisecond)))
+		}
+	}
+
+	rl.logger.LogAttrs(ctx, level, message, attrs...)
+}
+
+// RedactedIdentifier retorna o identificador usado em logs e auditoria, sem
+// dados do cliente em texto puro. Tokens aparecem como nas chaves do storage
+// (HMAC com TokenHashSecret) ou, sem segredo, pelo prefixo do SHA-256. IPs
+// são agregados pelo prefixo configurado e representados pelo prefixo do
+// HMAC com TokenHashSecret (SHA-256 sem segredo), a menos que
+// WithPlainIPLogging esteja habilitado.
+func (rl *RateLimiter) RedactedIdentifier(identifier string, limitType string) string {
+	if limitType == "token" && rl.config.TokenHashSecret == "" {
+		return rl.redactedHash(identifier)
+	}
+	return rl.RedactedKeyIdentifier(rl.KeyIdentifier(identifier, limitType), limitType)
+}
+
+// RedactedKeyIdentifier é como RedactedIdentifier, mas recebe o identificador
+// das chaves (ver KeyIdentifier), como retornado por ScanBlocks
+func (rl *RateLimiter) RedactedKeyIdentifier(keyIdentifier string, limitType string) string {
+	switch {
+	case limitType == "token" && rl.config.TokenHashSecret == "":
+		// Sem hash, o identificador das chaves é o próprio token
+		return rl.redactedHash(keyIdentifier)
+	case limitType == "ip" && !rl.plainIPLogging:
+		return rl.redactedHash(keyIdentifier)
+	}
+	return keyIdentifier
+}
+
+// redactedHash retorna o prefixo do HMAC-SHA256 do valor com TokenHashSecret
+// ou, sem segredo, o prefixo do SHA-256. O prefixo basta para correlacionar
+// eventos do mesmo cliente sem expor o valor original.
+func (rl *RateLimiter) redactedHash(value string) string {
+	if rl.config.TokenHashSecret == "" {
+		sum := sha256.Sum256([]byte(value))
+		return "sha256:" + hex.EncodeToString(sum[:8])
+	}
+	mac := hmac.New(sha256.New, []byte(rl.config.TokenHashSecret))
+	mac.Write([]byte(value))
+	return "hmac:" + hex.EncodeToString(mac.Sum(nil)[:8])
+}
@@ -0,0 +1,270 @@
+package limiter
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Status é o estado atual de um identificador no storage
+type Status struct {
+	LimitType     string
+	KeyIdentifier string
+	Rule          string
+	// Count é o número de requisições na janela atual
+	Count     int64
+	Limit     int64
+	Remaining int64
+	// BlockDuration é a duração do bloqueio aplicado ao exceder o limite
+	BlockDuration time.Duration
+	Blocked       bool
+	// BlockTTL é o tempo restante do bloqueio (zero se não bloqueado ou se o
+	// bloqueio não possui expiração)
+	BlockTTL time.Duration
+}
+
+// target identifica as chaves de um cliente nas operações de administração
+type target struct {
+	// identifier é usado na chave de bloqueio no formato antigo (LegacyKeyFallback)
+	identifier    string
+	keyIdentifier string
+	limitType     string
+}
+
+// target normaliza IPs como GetClientIP, para que as operações de
+// administração usem as mesmas chaves que CheckLimit (ex: ::ffff:1.2.3.4 e
+// [::1]:80 viram 1.2.3.4 e ::1)
+func (rl *RateLimiter) target(identifier string, limitType string) target {
+	if limitType == "ip" {
+		identifier = NormalizeIP(identifier)
+	}
+	return target{
+		identifier:    identifier,
+		keyIdentifier: rl.KeyIdentifier(identifier, limitType),
+		limitType:     limitType,
+	}
+}
+
+// keyTarget identifica o cliente pelo identificador das chaves, como
+// retornado por ScanBlocks e pelo tracker de maiores consumidores
+func keyTarget(keyIdentifier string, limitType string) target {
+	return target{
+		identifier:    keyIdentifier,
+		keyIdentifier: keyIdentifier,
+		limitType:     limitType,
+	}
+}
+
+// Inspect retorna o contador, a cota restante e o bloqueio de um identificador,
+// usando os limites do tier informado, sem contabilizar uma requisição
+func (rl *RateLimiter) Inspect(ctx context.Context, identifier string, limitType string, tier string) (*Status, error) {
+	return rl.inspect(ctx, rl.target(identifier, limitType), tier)
+}
+
+// InspectKey é como Inspect, mas recebe o identificador das chaves (ex: o hash
+// de um token), para clientes cujo token original não é conhecido
+func (rl *RateLimiter) InspectKey(ctx context.Context, keyIdentifier string, limitType string, tier string) (*Status, error) {
+	return rl.inspect(ctx, keyTarget(keyIdentifier, limitType), tier)
+}
+
+func (rl *RateLimiter) inspect(ctx context.Context, t target, tier string) (*Status, error) {
+	requestsPerSecond, blockDurationSeconds, rule, err := rl.limits(t.limitType, tier)
+	if err != nil {
+		return nil, err
+	}
+
+	key, blockKey := rl.storageKeysFor(t.keyIdentifier, t.limitType)
+
+	count, err := rl.storage.Get(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("error reading counter: %w", err)
+	}
+
+	activeBlockKey, err := rl.activeBlockKey(ctx, t.identifier, t.limitType, blockKey)
+	if err != nil {
+		return nil, fmt.Errorf("error checking block status: %w", err)
+	}
+
+	status := &Status{
+		LimitType:     t.limitType,
+		KeyIdentifier: t.keyIdentifier,
+		Rule:          rule,
+		Count:         count,
+		Limit:         int64(requestsPerSecond),
+		Remaining:     max(int64(requestsPerSecond)-count, 0),
+		BlockDuration: time.Duration(blockDurationSeconds) * time.Second,
+		Blocked:       activeBlockKey != "",
+	}
+	if status.Blocked {
+		status.Remaining = 0
+		status.BlockTTL, err = rl.storage.TTL(ctx, activeBlockKey)
+		if err != nil {
+			return nil, fmt.Errorf("error reading block expiration: %w", err)
+		}
+	}
+	return status, nil
+}
+
+// Limit retorna o limite de requisições por segundo do tipo de limite e do
+// tier informados
+func (rl *RateLimiter) Limit(limitType string, tier string) (int64, error) {
+	requestsPerSecond, _, _, err := rl.limits(limitType, tier)
+	return int64(requestsPerSecond), err
+}
+
+// Block bloqueia um identificador pela duração informada, substituindo um
+// bloqueio existente
+func (rl *RateLimiter) Block(ctx context.Context, identifier string, limitType string, duration time.Duration) error {
+	return rl.block(ctx, rl.target(identifier, limitType), duration)
+}
+
+// BlockKey é como Block, mas recebe o identificador das chaves
+func (rl *RateLimiter) BlockKey(ctx context.Context, keyIdentifier string, limitType string, duration time.Duration) error {
+	return rl.block(ctx, keyTarget(keyIdentifier, limitType), duration)
+}
+
+func (rl *RateLimiter) block(ctx context.Context, t target, duration time.Duration) error {
+	if _, _, _, err := rl.limits(t.limitType, ""); err != nil {
+		return err
+	}
+	if duration <= 0 {
+		return fmt.Errorf("invalid block duration: %s", duration)
+	}
+
+	_, blockKey := rl.storageKeysFor(t.keyIdentifier, t.limitType)
+	if err := rl.storage.Set(ctx, blockKey, 1, duration); err != nil {
+		return fmt.Errorf("error setting block: %w", err)
+	}
+	return nil
+}
+
+// Unblock remove o bloqueio de um identificador, inclusive a chave no formato
+// antigo durante a migração de chaves (LegacyKeyFallback) e o bloqueio
+// simulado de regras em shadow mode
+func (rl *RateLimiter) Unblock(ctx context.Context, identifier string, limitType string) error {
+	return rl.unblock(ctx, rl.target(identifier, limitType))
+}
+
+// UnblockKey é como Unblock, mas recebe o identificador das chaves
+func (rl *RateLimiter) UnblockKey(ctx context.Context, keyIdentifier string, limitType string) error {
+	return rl.unblock(ctx, keyTarget(keyIdentifier, limitType))
+}
+
+func (rl *RateLimiter) unblock(ctx context.Context, t target) error {
+	if _, _, _, err := rl.limits(t.limitType, ""); err != nil {
+		return err
+	}
+
+	_, blockKey := rl.storageKeysFor(t.keyIdentifier, t.limitType)
+	if err := rl.storage.Delete(ctx, blockKey); err != nil {
+		return fmt.Errorf("error removing block: %w", err)
+	}
+	if err := rl.storage.Delete(ctx, rl.shadowBlockKey(t.keyIdentifier, t.limitType)); err != nil {
+		return fmt.Errorf("error removing shadow block: %w", err)
+	}
+
+	if legacyKey := legacyBlockKey(t.identifier, t.limitType); rl.config.LegacyKeyFallback && legacyKey != blockKey {
+		if err := rl.storage.Delete(ctx, legacyKey); err != nil {
+			return fmt.Errorf("error removing block: %w", err)
+		}
+	}
+	return nil
+}
+
+// ResetCounter zera o contador de requisições de um identificador. Bloqueios
+// ativos não são afetados (ver Unblock).
+func (rl *RateLimiter) ResetCounter(ctx context.Context, identifier string, limitType string) error {
+	return rl.resetCounter(ctx, rl.target(identifier, limitType))
+}
+
+// ResetCounterKey é como ResetCounter, mas recebe o identificador das chaves
+func (rl *RateLimiter) ResetCounterKey(ctx context.Context, keyIdentifier string, limitType string) error {
+	return rl.resetCounter(ctx, keyTarget(keyIdentifier, limitType))
+}
+
+func (rl *RateLimiter) resetCounter(ctx context.Context, t target) error {
+	if _, _, _, err := rl.limits(t.limitType, ""); err != nil {
+		return err
+	}
+
+	key, _ := rl.storageKeysFor(t.keyIdentifier, t.limitType)
+	if err := rl.storage.Delete(ctx, key); err != nil {
+		return fmt.Errorf("error resetting counter: %w", err)
+	}
+	return nil
+}
+
+// BlockEntry é um bloqueio ativo encontrado no storage
+type BlockEntry struct {
+	LimitType string
+	// KeyIdentifier é o identificador usado na chave (tokens com hash, ver KeyIdentifier)
+	KeyIdentifier string
+	// TTL é o tempo restante do bloqueio (zero se não possui expiração)
+	TTL time.Duration
+}
+
+// ScanBlocks lista os bloqueios ativos do namespace configurado, uma página
+// por chamada, usando o Scan do storage. Comece com cursor 0 e continue com o
+// cursor retornado até ele ser 0; as páginas podem ter mais ou menos que
+// count entradas.
+func (rl *RateLimiter) ScanBlocks(ctx context.Context, cursor uint64, count int64) ([]BlockEntry, uint64, error) {
+	prefix := rl.namespaced("block:")
+	keys, next, err := rl.storage.Scan(ctx, cursor, escapeGlob(prefix)+"*", count)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error scanning blocks: %w", err)
+	}
+
+	blocks := make([]BlockEntry, 0, len(keys))
+	for _, key := range keys {
+		limitType, keyIdentifier, ok := strings.Cut(strings.TrimPrefix(key, prefix), ":")
+		if !ok {
+			continue
+		}
+
+		ttl, err := rl.storage.TTL(ctx, key)
+		if err != nil {
+			return nil, 0, fmt.Errorf("error reading block expiration: %w", err)
+		}
+		blocks = append(blocks, BlockEntry{
+			LimitType:     limitType,
+			KeyIdentifier: keyIdentifier,
+			TTL:           ttl,
+		})
+	}
+	return blocks, next, nil
+}
+
+// countBlocks conta os bloqueios ativos do namespace por tipo de limite,
+// para a métrica active_blocks. Chaves repetidas pelo Scan são contadas uma vez.
+func (rl *RateLimiter) countBlocks(ctx context.Context) (map[string]int, error) {
+	prefix := rl.namespaced("block:")
+	counts := map[string]int{"ip": 0, "token": 0}
+	seen := make(map[string]struct{})
+
+	var cursor uint64
+	for {
+		keys, next, err := rl.storage.Scan(ctx, cursor, escapeGlob(prefix)+"*", 1000)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning blocks: %w", err)
+		}
+		for _, key := range keys {
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			if limitType, _, ok := strings.Cut(strings.TrimPrefix(key, prefix), ":"); ok {
+				counts[limitType]++
+			}
+		}
+		if next == 0 {
+			return counts, nil
+		}
+		cursor = next
+	}
+}
+
+// escapeGlob escapa os caracteres especiais de padrões glob do Scan
+func escapeGlob(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`, "]", `\]`).Replace(value)
+}
@@ -0,0 +1,128 @@
+package limiter
+
+import "net/netip"
+
+// DefaultConfig retorna os limites padrão do servidor: 10 req/s por IP com
+// bloqueio de 5 minutos, 100 req/s por token com bloqueio de 10 minutos, e
+// IPv6 agregado por /64
+func DefaultConfig() *Config {
+	return &Config{
+		IPRequestsPerSecond:       10,
+		IPBlockDurationSeconds:    300,
+		TokenRequestsPerSecond:    100,
+		TokenBlockDurationSeconds: 600,
+		IPv4PrefixLength:          32,
+		IPv6PrefixLength:          64,
+	}
+}
+
+// New cria o rate limiter a partir de DefaultConfig, ajustado pelas opções:
+//
+//	rl := limiter.New(storage,
+//		limiter.WithIPLimit(5, 60),
+//		limiter.WithTokenLimit(50, 120),
+//		limiter.WithTier("pro", limiter.Tier{RequestsPerSecond: 500, BlockDurationSeconds: 60}),
+//	)
+func New(storage StorageStrategy, opts ...Option) *RateLimiter {
+	return NewRateLimiter(storage, DefaultConfig(), opts...)
+}
+
+// WithConfig substitui toda a configuração. Opções informadas depois dela
+// continuam ajustando a nova configuração.
+func WithConfig(config Config) Option {
+	return func(rl *RateLimiter) {
+		rl.config = &config
+	}
+}
+
+// WithIPLimit define o limite de requisições por segundo por IP e a duração
+// do bloqueio, em segundos, ao excedê-lo
+func WithIPLimit(requestsPerSecond, blockDurationSeconds int) Option {
+	return func(rl *RateLimiter) {
+		rl.config.IPRequestsPerSecond = requestsPerSecond
+		rl.config.IPBlockDurationSeconds = blockDurationSeconds
+	}
+}
+
+// WithTokenLimit define o limite de requisições por segundo por token e a
+// duração do bloqueio, em segundos, ao excedê-lo
+func WithTokenLimit(requestsPerSecond, blockDurationSeconds int) Option {
+	return func(rl *RateLimiter) {
+		rl.config.TokenRequestsPerSecond = requestsPerSecond
+		rl.config.TokenBlockDurationSeconds = blockDurationSeconds
+	}
+}
+
+// WithTier adiciona um plano de limites por token (ver Config.Tiers)
+func WithTier(name string, tier Tier) Option {
+	return func(rl *RateLimiter) {
+		if rl.config.Tiers == nil {
+			rl.config.Tiers = make(map[string]Tier)
+		}
+		rl.config.Tiers[name] = tier
+	}
+}
+
+// WithIPPrefixLengths agrega os IPs por prefixo de rede (ver Config.IPv4PrefixLength)
+func WithIPPrefixLengths(ipv4, ipv6 int) Option {
+	return func(rl *RateLimiter) {
+		rl.config.IPv4PrefixLength = ipv4
+		rl.config.IPv6PrefixLength = ipv6
+	}
+}
+
+// WithTokenHeaders define os headers consultados, em ordem, para obter o token
+func WithTokenHeaders(names ...string) Option {
+	return func(rl *RateLimiter) {
+		rl.config.TokenHeaders = names
+	}
+}
+
+// WithTokenQueryParam lê o token do parâmetro de query quando nenhum header o contém
+func WithTokenQueryParam(param string) Option {
+	return func(rl *RateLimiter) {
+		rl.config.TokenQueryParam = param
+	}
+}
+
+// WithRejectUnknownTokens rejeita tokens não reconhecidos pelo key store ou
+// pelo verificador, em vez de aplicar o limite por IP
+func WithRejectUnknownTokens() Option {
+	return func(rl *RateLimiter) {
+		rl.config.RejectUnknownTokens = true
+	}
+}
+
+// WithTokenHashSecret aplica HMAC aos tokens antes de usá-los nas chaves do storage
+func WithTokenHashSecret(secret string) Option {
+	return func(rl *RateLimiter) {
+		rl.config.TokenHashSecret = secret
+	}
+}
+
+// WithTrustedProxies aceita os headers de encaminhamento (X-Forwarded-For,
+// X-Real-IP e X-Client-IP) apenas de conexões vindas das redes informadas
+// (ver Config.TrustedProxies)
+func WithTrustedProxies(prefixes ...netip.Prefix) Option {
+	return func(rl *RateLimiter) {
+		rl.config.TrustedProxies = append(rl.config.TrustedProxies, prefixes...)
+	}
+}
+
+// WithShadowRule avalia o limite candidato informado para a regra em shadow
+// mode, sem deixar de aplicar o limite atual (ver Config.ShadowRules)
+func WithShadowRule(rule string, candidate Tier) Option {
+	return func(rl *RateLimiter) {
+		if rl.config.ShadowRules == nil {
+			rl.config.ShadowRules = make(map[string]Tier)
+		}
+		rl.config.ShadowRules[rule] = candidate
+	}
+}
+
+// WithKeyNamespace prefixa todas as chaves do storage
+func WithKeyNamespace(namespace string) Option {
+	return func(rl *RateLimiter) {
+		rl.config.KeyNamespace = namespace
+	}
+}
@@ -0,0 +1,41 @@
+package limiter
+
+import "sync/atomic"
+
+// DecisionCount é o total de decisões de um tipo de limite desde a criação do
+// RateLimiter, nesta instância
+type DecisionCount struct {
+	Allowed int64
+	Denied  int64
+}
+
+// decisionCounters conta as decisões sem travas, para uso no caminho da requisição
+type decisionCounters struct {
+	ipAllowed    atomic.Int64
+	ipDenied     atomic.Int64
+	tokenAllowed atomic.Int64
+	tokenDenied  atomic.Int64
+}
+
+func (d *decisionCounters) add(limitType string, allowed bool) {
+	switch {
+	case limitType == "ip" && allowed:
+		d.ipAllowed.Add(1)
+	case limitType == "ip":
+		d.ipDenied.Add(1)
+	case allowed:
+		d.tokenAllowed.Add(1)
+	default:
+		d.tokenDenied.Add(1)
+	}
+}
+
+// Decisions retorna os totais de decisões por tipo de limite ("ip" e
+// "token"). Os totais são desta instância; para o total de todas as
+// instâncias, use as métricas Prometheus (WithMetrics).
+func (rl *RateLimiter) Decisions() map[string]DecisionCount {
+	return map[string]DecisionCount{
+		"ip":    {Allowed: rl.decisions.ipAllowed.Load(), Denied: rl.decisions.ipDenied.Load()},
+		"token": {Allowed: rl.decisions.tokenAllowed.Load(), Denied: rl.decisions.tokenDenied.Load()},
+	}
+}
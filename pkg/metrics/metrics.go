@@ -0,0 +1,215 @@
+// Package metrics expõe as métricas Prometheus do rate limiter: decisões,
+// latência das verificações e do storage, bloqueios ativos, erros de storage,
+// negações de regras em shadow mode e eventos de auditoria descartados.
+package metrics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const namespace = "rate_limiter"
+
+// blockCountTimeout limita a leitura dos bloqueios ativos
+const blockCountTimeout = 5 * time.Second
+
+// defaultBlockCountInterval é o intervalo padrão entre contagens dos bloqueios
+// ativos no storage
+const defaultBlockCountInterval = 30 * time.Second
+
+// BlockCounter conta os bloqueios ativos no storage por tipo de limite
+type BlockCounter func(ctx context.Context) (map[string]int, error)
+
+// Metrics agrupa os coletores usados pelo limiter e pelo storage. Os métodos
+// aceitam receptor nil, para que a instrumentação seja opcional.
+type Metrics struct {
+	decisions       *prometheus.CounterVec
+	checkDuration   *prometheus.HistogramVec
+	storageDuration *prometheus.HistogramVec
+	storageErrors   *prometheus.CounterVec
+	activeBlocks    *blocksCollector
+	shadowDenials   *prometheus.CounterVec
+	auditDrops      prometheus.Counter
+}
+
+// Option configura Metrics
+type Option func(*Metrics)
+
+// WithBlockCountInterval define por quanto tempo a contagem dos bloqueios
+// ativos é reaproveitada entre coletas (padrão: 30s). Com intervalo zero ou
+// negativo a instância não publica active_blocks, o que permite contar em
+// uma única instância.
+func WithBlockCountInterval(interval time.Duration) Option {
+	return func(m *Metrics) {
+		m.activeBlocks.interval = interval
+	}
+}
+
+// New cria os coletores e os registra no registerer informado
+func New(registerer prometheus.Registerer, opts ...Option) *Metrics {
+	m := &Metrics{
+		decisions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "decisions_total",
+			Help:      "Rate limit decisions by limit type, rule and result (allowed or denied).",
+		}, []string{"limit_type", "rule", "decision"}),
+		checkDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "check_duration_seconds",
+			Help:      "Duration of CheckLimit calls, including storage round trips.",
+			Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+		}, []string{"limit_type"}),
+		storageDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "storage_operation_duration_seconds",
+			Help:      "Duration of storage operations.",
+			Buckets:   []float64{.0002, .0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5},
+		}, []string{"operation"}),
+		storageErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "storage_errors_total",
+			Help:      "Storage operations that returned an error.",
+		}, []string{"operation"}),
+		activeBlocks: &blocksCollector{
+			desc: prometheus.NewDesc(
+				prometheus.BuildFQName(namespace, "", "active_blocks"),
+				"Active blocks in storage by limit type.",
+				[]string{"limit_type"}, nil,
+			),
+			interval: defaultBlockCountInterval,
+		},
+		shadowDenials: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "shadow_denials_total",
+			Help:      "Requests that a candidate limit in shadow mode would have denied.",
+		}, []string{"limit_type", "rule"}),
+		auditDrops: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "audit_events_dropped_total",
+			Help:      "Audit events dropped because the queue was full or delivery failed.",
+		}),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	registerer.MustRegister(m.decisions, m.checkDuration, m.storageDuration, m.storageErrors, m.activeBlocks, m.shadowDenials, m.auditDrops)
+	return m
+}
+
+// ObserveDecision conta uma decisão do limiter
+func (m *Metrics) ObserveDecision(limitType, rule string, allowed bool) {
+	if m == nil {
+		return
+	}
+	decision := "denied"
+	if allowed {
+		decision = "allowed"
+	}
+	m.decisions.WithLabelValues(limitType, rule, decision).Inc()
+}
+
+// ObserveShadowDenial conta uma requisição que o limite candidato de uma regra
+// em shadow mode negaria. A decisão real é contada por ObserveDecision.
+func (m *Metrics) ObserveShadowDenial(limitType, rule string) {
+	if m == nil {
+		return
+	}
+	m.shadowDenials.WithLabelValues(limitType, rule).Inc()
+}
+
+// ObserveAuditDrops conta eventos de auditoria descartados
+func (m *Metrics) ObserveAuditDrops(count int) {
+	if m == nil {
+		return
+	}
+	m.auditDrops.Add(float64(count))
+}
+
+// ObserveCheck registra a duração de uma verificação de limite
+func (m *Metrics) ObserveCheck(limitType string, duration time.Duration) {
+	if m == nil {
+		return
+	}
+	m.checkDuration.WithLabelValues(limitType).Observe(duration.Seconds())
+}
+
+// ObserveStorage registra a duração de uma operação do storage e, se houve
+// erro, incrementa o contador de erros da operação
+func (m *Metrics) ObserveStorage(operation string, duration time.Duration, err error) {
+	if m == nil {
+		return
+	}
+	m.storageDuration.WithLabelValues(operation).Observe(duration.Seconds())
+	if err != nil {
+		m.storageErrors.WithLabelValues(operation).Inc()
+	}
+}
+
+// CountBlocks define como os bloqueios ativos são contados. A contagem é
+// feita no storage, de modo que desbloqueios, bloqueios renovados e bloqueios
+// de outras instâncias são refletidos, e reaproveitada pelas coletas do
+// intervalo definido em WithBlockCountInterval.
+func (m *Metrics) CountBlocks(counter BlockCounter) {
+	if m == nil {
+		return
+	}
+	m.activeBlocks.mu.Lock()
+	defer m.activeBlocks.mu.Unlock()
+	m.activeBlocks.counter = counter
+	m.activeBlocks.counts = nil
+}
+
+// blocksCollector publica o gauge active_blocks a partir do BlockCounter,
+// guardando a última contagem para não varrer o storage a cada coleta
+type blocksCollector struct {
+	desc     *prometheus.Desc
+	interval time.Duration
+
+	mu        sync.Mutex
+	counter   BlockCounter
+	counts    map[string]int
+	countedAt time.Time
+}
+
+func (c *blocksCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *blocksCollector) Collect(ch chan<- prometheus.Metric) {
+	counts, err := c.count()
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(c.desc, err)
+		return
+	}
+	for limitType, count := range counts {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(count), limitType)
+	}
+}
+
+// count devolve a última contagem enquanto ela estiver dentro do intervalo.
+// Coletas simultâneas esperam a mesma contagem em vez de varrer o storage
+// cada uma; falhas não são guardadas.
+func (c *blocksCollector) count() (map[string]int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.counter == nil || c.interval <= 0 {
+		return nil, nil
+	}
+	if c.counts != nil && time.Since(c.countedAt) < c.interval {
+		return c.counts, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), blockCountTimeout)
+	defer cancel()
+	counts, err := c.counter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.counts = counts
+	c.countedAt = time.Now()
+	return counts, nil
+}
@@ -0,0 +1,114 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetrics(t *testing.T) {
+	m := New(prometheus.NewRegistry())
+
+	m.ObserveDecision("ip", "ip", true)
+	m.ObserveDecision("ip", "ip", false)
+	m.ObserveDecision("token", "token:pro", false)
+	m.ObserveStorage("get", time.Millisecond, nil)
+	m.ObserveStorage("get", time.Millisecond, errors.New("connection refused"))
+	m.ObserveCheck("ip", 2*time.Millisecond)
+	m.ObserveShadowDenial("token", "token:free")
+
+	tests := []struct {
+		name     string
+		value    float64
+		expected float64
+	}{
+		{name: "allowed ip decisions", value: testutil.ToFloat64(m.decisions.WithLabelValues("ip", "ip", "allowed")), expected: 1},
+		{name: "denied ip decisions", value: testutil.ToFloat64(m.decisions.WithLabelValues("ip", "ip", "denied")), expected: 1},
+		{name: "denied tier decisions", value: testutil.ToFloat64(m.decisions.WithLabelValues("token", "token:pro", "denied")), expected: 1},
+		{name: "shadow denials", value: testutil.ToFloat64(m.shadowDenials.WithLabelValues("token", "token:free")), expected: 1},
+		{name: "storage errors", value: testutil.ToFloat64(m.storageErrors.WithLabelValues("get")), expected: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.value != tt.expected {
+				t.Errorf("value = %v, expected %v", tt.value, tt.expected)
+			}
+		})
+	}
+
+	if count := testutil.CollectAndCount(m.storageDuration); count != 1 {
+		t.Errorf("storage duration series = %d, expected 1", count)
+	}
+	if count := testutil.CollectAndCount(m.checkDuration); count != 1 {
+		t.Errorf("check duration series = %d, expected 1", count)
+	}
+}
+
+func TestMetrics_ActiveBlocks(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	m := New(registry)
+
+	// Sem BlockCounter, a métrica não é publicada
+	if count, err := testutil.GatherAndCount(registry, "rate_limiter_active_blocks"); err != nil || count != 0 {
+		t.Errorf("active blocks series without counter = %d (err: %v), expected 0", count, err)
+	}
+
+	counts := map[string]int{"ip": 2, "token": 0}
+	calls := 0
+	m.CountBlocks(func(ctx context.Context) (map[string]int, error) {
+		calls++
+		return counts, nil
+	})
+	expected := `
+# HELP rate_limiter_active_blocks Active blocks in storage by limit type.
+# TYPE rate_limiter_active_blocks gauge
+rate_limiter_active_blocks{limit_type="ip"} 2
+rate_limiter_active_blocks{limit_type="token"} 0
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expected), "rate_limiter_active_blocks"); err != nil {
+		t.Errorf("unexpected metrics: %v", err)
+	}
+
+	// Dentro do intervalo, a coleta reaproveita a última contagem
+	if _, err := registry.Gather(); err != nil || calls != 1 {
+		t.Errorf("counter calls after two scrapes = %d (err: %v), expected 1", calls, err)
+	}
+
+	// Falhas na contagem são reportadas na coleta
+	m.CountBlocks(func(ctx context.Context) (map[string]int, error) {
+		return nil, errors.New("connection refused")
+	})
+	if _, err := registry.Gather(); err == nil {
+		t.Error("Gather() with a failing counter should return an error")
+	}
+}
+
+func TestMetrics_ActiveBlocksDisabled(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	m := New(registry, WithBlockCountInterval(0))
+
+	m.CountBlocks(func(ctx context.Context) (map[string]int, error) {
+		t.Error("BlockCounter should not be called with a zero interval")
+		return nil, nil
+	})
+	if count, err := testutil.GatherAndCount(registry, "rate_limiter_active_blocks"); err != nil || count != 0 {
+		t.Errorf("active blocks series with a zero interval = %d (err: %v), expected 0", count, err)
+	}
+}
+
+func TestMetrics_Nil(t *testing.T) {
+	var m *Metrics
+
+	// Sem métricas configuradas, a instrumentação não faz nada
+	m.ObserveDecision("ip", "ip", true)
+	m.ObserveCheck("ip", time.Millisecond)
+	m.ObserveStorage("get", time.Millisecond, nil)
+	m.CountBlocks(nil)
+	m.ObserveShadowDenial("ip", "ip")
+}
@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/m4rcelotoledo/rate-limiter/pkg/access"
+	"github.com/m4rcelotoledo/rate-limiter/pkg/limiter"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/m4rcelotoledo/rate-limiter/pkg/middleware"
+
+// Decider concentra a decisão do rate limiter sobre uma requisição, sem
+// depender de framework: os middlewares de Gin, net/http, Echo e Fiber apenas
+// convertem a requisição e escrevem a resposta retornada.
+type Decider struct {
+	rateLimiter *limiter.RateLimiter
+	options     *options
+	tracer      trace.Tracer
+}
+
+// NewDecider cria o Decider com as mesmas opções aceitas pelos middlewares
+func NewDecider(rateLimiter *limiter.RateLimiter, opts ...Option) *Decider {
+	options := newOptions(opts)
+	return &Decider{
+		rateLimiter: rateLimiter,
+		options:     options,
+		tracer:      options.tracerProvider.Tracer(instrumentationName),
+	}
+}
+
+// Decide aplica listas de acesso, validação do token e limite à requisição,
+// adicionando os headers de rate limit em header. Retorna nil quando a
+// requisição deve seguir, ou a resposta a ser enviada no lugar do handler.
+func (d *Decider) Decide(r *http.Request, header http.Header) *Rejection {
+	_, rejection := d.DecideResult(r, header)
+	return rejection
+}
+
+// DecideResult é como Decide, mas também retorna o resultado do limite, para
+// adapters que montam a própria resposta (ex: o interceptor gRPC). O
+// resultado é nil quando a requisição é decidida antes do limite: listas de
+// acesso, token inválido ou falha na validação.
+func (d *Decider) DecideResult(r *http.Request, header http.Header) (*limiter.LimitResult, *Rejection) {
+	ctx, span := d.tracer.Start(r.Context(), "rate_limiter.decide")
+	defer span.End()
+
+	result, rejection := d.decide(r.WithContext(ctx), header, span)
+	if rejection != nil {
+		span.SetAttributes(attribute.Int("http.response.status_code", rejection.StatusCode))
+		if rejection.StatusCode >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(rejection.StatusCode))
+		}
+	}
+	return result, rejection
+}
+
+func (d *Decider) decide(r *http.Request, header http.Header, span trace.Span) (*limiter.LimitResult, *Rejection) {
+	ctx := r.Context()
+	rateLimiter, options := d.rateLimiter, d.options
+
+	// Identifica o cliente: consulta as listas de acesso e valida o token
+	// (key store ou JWT); tokens desconhecidos caem no limite por IP ou são
+	// rejeitados, conforme a configuração
+	identity, err := rateLimiter.Identify(ctx, r)
+	span.SetAttributes(attribute.String("rate_limiter.access", identity.Access.String()))
+	switch identity.Access {
+	case access.Deny:
+		return nil, errorRejection(http.StatusForbidden, "access denied")
+	case access.Allow:
+		return nil, nil
+	}
+	if errors.Is(err, limiter.ErrUnknownToken) {
+		return nil, errorRejection(http.StatusUnauthorized, "invalid API key")
+	}
+	if err != nil {
+		return nil, errorRejection(http.StatusInternalServerError, "Internal server error")
+	}
+
+	identifier, limitType, tier := identity.Identifier, identity.LimitType, identity.Tier
+
+	span.SetAttributes(attribute.String("rate_limiter.limit_type", limitType))
+	result, err := rateLimiter.CheckLimitWithTier(ctx, identifier, limitType, tier)
+	if err != nil {
+		span.RecordError(err)
+		return nil, errorRejection(http.StatusInternalServerError, "Internal server error")
+	}
+	span.SetAttributes(
+		attribute.String("rate_limiter.rule", result.Rule),
+		attribute.Bool("rate_limiter.allowed", result.Allowed),
+		attribute.Int64("rate_limiter.remaining", result.Remaining),
+	)
+
+	// Adiciona headers de rate limit
+	setRateLimitHeaders(header, result, options.headers)
+
+	// Limites candidatos em shadow mode não alteram a resposta: apenas
+	// sinalizam a negação que teria ocorrido
+	if result.Shadow {
+		span.SetAttributes(attribute.Bool("rate_limiter.shadow_denied", result.ShadowDenied))
+		if result.ShadowDenied {
+			setShadowHeader(header, result)
+		}
+	}
+
+	if !result.Allowed {
+		setRetryAfterHeader(header, result)
+		rejection := options.rejectionHandler(r, result)
+		return result, &rejection
+	}
+
+	return result, nil
+}
+
+// errorRejection monta as respostas de erro fora do limite (acesso negado,
+// token inválido, falha no storage) no formato {"error": "<mensagem>"}
+func errorRejection(statusCode int, message string) *Rejection {
+	rejection := jsonRejection(statusCode, "application/json; charset=utf-8", map[string]string{
+		"error": message,
+	})
+	return &rejection
+}
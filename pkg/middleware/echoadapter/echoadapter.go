@@ -0,0 +1,25 @@
+// Package echoadapter expõe o rate limiter como middleware do Echo
+package echoadapter
+
+import (
+	"github.com/m4rcelotoledo/rate-limiter/pkg/limiter"
+	"github.com/m4rcelotoledo/rate-limiter/pkg/middleware"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RateLimiter é o equivalente de middleware.RateLimiterMiddleware para o Echo,
+// com a mesma decisão, headers e respostas de erro
+func RateLimiter(rateLimiter *limiter.RateLimiter, opts ...middleware.Option) echo.MiddlewareFunc {
+	decider := middleware.NewDecider(rateLimiter, opts...)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if rejection := decider.Decide(c.Request(), c.Response().Header()); rejection != nil {
+				return c.Blob(rejection.StatusCode, rejection.ContentType, rejection.Body)
+			}
+
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package echoadapter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/m4rcelotoledo/rate-limiter/internal/limitertest"
+	"github.com/m4rcelotoledo/rate-limiter/pkg/limiter"
+	"github.com/m4rcelotoledo/rate-limiter/pkg/middleware"
+
+	"github.com/labstack/echo/v4"
+)
+
+func newTestServer(rateLimiter *limiter.RateLimiter, opts ...middleware.Option) *echo.Echo {
+	e := echo.New()
+	e.Use(RateLimiter(rateLimiter, opts...))
+	e.GET("/", func(c echo.Context) error {
+		return c.String(http.StatusOK, "ok")
+	})
+	return e
+}
+
+func TestRateLimiter(t *testing.T) {
+	e := newTestServer(limitertest.NewRateLimiter(t))
+
+	limitertest.RunAdapterConformance(t, func(t *testing.T, req *http.Request) *http.Response {
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, req)
+		return w.Result()
+	})
+}
+
+func TestRateLimiter_Options(t *testing.T) {
+	rejectionHandler, err := middleware.NewRejectionHandler(middleware.RejectionConfig{
+		Format:     middleware.ProblemFormat,
+		StatusCode: http.StatusServiceUnavailable,
+	})
+	if err != nil {
+		t.Fatalf("NewRejectionHandler() error = %v", err)
+	}
+	e := newTestServer(limitertest.NewRateLimiter(t),
+		middleware.WithHeaders(middleware.IETFHeaders),
+		middleware.WithRejectionHandler(rejectionHandler),
+	)
+
+	var w *httptest.ResponseRecorder
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = "198.51.100.7:1234"
+		w = httptest.NewRecorder()
+		e.ServeHTTP(w, req)
+	}
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, expected %d", w.Code, http.StatusServiceUnavailable)
+	}
+	if got := w.Header().Get("Content-Type"); got != "application/problem+json" {
+		t.Errorf("Content-Type = %q, expected %q", got, "application/problem+json")
+	}
+	if got := w.Header().Get("RateLimit-Policy"); got != `"ip";q=2;w=1` {
+		t.Errorf("RateLimit-Policy = %q, expected %q", got, `"ip";q=2;w=1`)
+	}
+	if got := w.Header().Get("X-RateLimit-Limit"); got != "" {
+		t.Errorf("X-RateLimit-Limit = %q, expected no legacy headers", got)
+	}
+}
@@ -0,0 +1,43 @@
+// Package fiberadapter expõe o rate limiter como middleware do Fiber
+package fiberadapter
+
+import (
+	"net/http"
+
+	"github.com/m4rcelotoledo/rate-limiter/pkg/limiter"
+	"github.com/m4rcelotoledo/rate-limiter/pkg/middleware"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp/fasthttpadaptor"
+)
+
+// RateLimiter é o equivalente de middleware.RateLimiterMiddleware para o Fiber,
+// com a mesma decisão, headers e respostas de erro
+func RateLimiter(rateLimiter *limiter.RateLimiter, opts ...middleware.Option) fiber.Handler {
+	decider := middleware.NewDecider(rateLimiter, opts...)
+
+	return func(c *fiber.Ctx) error {
+		// O Fiber usa fasthttp; a decisão trabalha sobre net/http
+		var r http.Request
+		if err := fasthttpadaptor.ConvertRequest(c.Context(), &r, true); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Internal server error",
+			})
+		}
+
+		header := make(http.Header)
+		rejection := decider.Decide(r.WithContext(c.UserContext()), header)
+		for name, values := range header {
+			for _, value := range values {
+				c.Response().Header.Add(name, value)
+			}
+		}
+
+		if rejection != nil {
+			c.Set(fiber.HeaderContentType, rejection.ContentType)
+			return c.Status(rejection.StatusCode).Send(rejection.Body)
+		}
+
+		return c.Next()
+	}
+}
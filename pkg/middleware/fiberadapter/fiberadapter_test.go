@@ -0,0 +1,89 @@
+package fiberadapter
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/m4rcelotoledo/rate-limiter/internal/limitertest"
+	"github.com/m4rcelotoledo/rate-limiter/pkg/limiter"
+	"github.com/m4rcelotoledo/rate-limiter/pkg/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// fiberTestProxy confia no X-Forwarded-For das requisições de app.Test, que
+// chegam do endereço 0.0.0.0
+var fiberTestProxy = limiter.WithTrustedProxies(netip.MustParsePrefix("0.0.0.0/32"))
+
+func newTestApp(rateLimiter *limiter.RateLimiter, opts ...middleware.Option) *fiber.App {
+	app := fiber.New()
+	app.Use(RateLimiter(rateLimiter, opts...))
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.SendString("ok")
+	})
+	return app
+}
+
+func doRequest(t *testing.T, app *fiber.App, req *http.Request) (*http.Response, string) {
+	t.Helper()
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("app.Test() error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read body: %v", err)
+	}
+	return resp, string(body)
+}
+
+func TestRateLimiter(t *testing.T) {
+	app := newTestApp(limitertest.NewRateLimiter(t, fiberTestProxy))
+
+	limitertest.RunAdapterConformance(t, func(t *testing.T, req *http.Request) *http.Response {
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("app.Test() error = %v", err)
+		}
+		return resp
+	})
+}
+
+func TestRateLimiter_Options(t *testing.T) {
+	rejectionHandler, err := middleware.NewRejectionHandler(middleware.RejectionConfig{
+		Format:     middleware.ProblemFormat,
+		StatusCode: http.StatusServiceUnavailable,
+	})
+	if err != nil {
+		t.Fatalf("NewRejectionHandler() error = %v", err)
+	}
+	app := newTestApp(limitertest.NewRateLimiter(t, fiberTestProxy),
+		middleware.WithHeaders(middleware.IETFHeaders),
+		middleware.WithRejectionHandler(rejectionHandler),
+	)
+
+	var resp *http.Response
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-Forwarded-For", "198.51.100.7")
+		resp, _ = doRequest(t, app, req)
+	}
+
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, expected %d", resp.StatusCode, http.StatusServiceUnavailable)
+	}
+	if got := resp.Header.Get("Content-Type"); got != "application/problem+json" {
+		t.Errorf("Content-Type = %q, expected %q", got, "application/problem+json")
+	}
+	if got := resp.Header.Get("RateLimit-Policy"); got != `"ip";q=2;w=1` {
+		t.Errorf("RateLimit-Policy = %q, expected %q", got, `"ip";q=2;w=1`)
+	}
+	if got := resp.Header.Get("X-RateLimit-Limit"); got != "" {
+		t.Errorf("X-RateLimit-Limit = %q, expected no legacy headers", got)
+	}
+}
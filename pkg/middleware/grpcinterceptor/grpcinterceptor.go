@@ -0,0 +1,168 @@
+// Package grpcinterceptor expõe o rate limiter como interceptors de servidor gRPC
+package grpcinterceptor
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/m4rcelotoledo/rate-limiter/pkg/limiter"
+	"github.com/m4rcelotoledo/rate-limiter/pkg/middleware"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// Option configura os interceptors
+type Option func(*options)
+
+type options struct {
+	middlewareOptions []middleware.Option
+}
+
+// WithMiddlewareOptions aplica as opções do middleware HTTP (headers enviados
+// como metadados, tracer) à decisão dos interceptors
+func WithMiddlewareOptions(opts ...middleware.Option) Option {
+	return func(o *options) {
+		o.middlewareOptions = append(o.middlewareOptions, opts...)
+	}
+}
+
+// interceptor guarda a decisão compartilhada pelos interceptors unário e de stream
+type interceptor struct {
+	decider *middleware.Decider
+}
+
+func newInterceptor(rateLimiter *limiter.RateLimiter, opts []Option) *interceptor {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return &interceptor{
+		decider: middleware.NewDecider(rateLimiter, o.middlewareOptions...),
+	}
+}
+
+// UnaryServerInterceptor aplica o rate limiter a chamadas unárias
+func UnaryServerInterceptor(rateLimiter *limiter.RateLimiter, opts ...Option) grpc.UnaryServerInterceptor {
+	i := newInterceptor(rateLimiter, opts)
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		header, err := i.check(ctx)
+		if len(header) > 0 {
+			grpc.SetHeader(ctx, header)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor aplica o rate limiter na abertura de cada stream
+func StreamServerInterceptor(rateLimiter *limiter.RateLimiter, opts ...Option) grpc.StreamServerInterceptor {
+	i := newInterceptor(rateLimiter, opts)
+
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		header, err := i.check(ss.Context())
+		if len(header) > 0 {
+			ss.SetHeader(header)
+		}
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, ss)
+	}
+}
+
+// check aplica a decisão do middleware HTTP (listas de acesso, validação do
+// token e limite) à chamada. Retorna os headers de rate limit como metadados
+// e o erro gRPC, se a chamada for rejeitada.
+func (i *interceptor) check(ctx context.Context) (metadata.MD, error) {
+	// Os metadados e o endereço do peer são lidos como uma requisição HTTP,
+	// para que a extração de token e IP seja a mesma do middleware HTTP
+	r := i.requestFromContext(ctx)
+	header := make(http.Header)
+	result, rejection := i.decider.DecideResult(r, header)
+
+	md := metadata.MD{}
+	for name, values := range header {
+		md.Append(name, values...)
+	}
+	if rejection == nil {
+		return md, nil
+	}
+
+	switch {
+	case result != nil:
+		return md, resourceExhausted(result)
+	case rejection.StatusCode == http.StatusForbidden:
+		return md, status.Error(codes.PermissionDenied, "access denied")
+	case rejection.StatusCode == http.StatusUnauthorized:
+		return md, status.Error(codes.Unauthenticated, "invalid API key")
+	default:
+		return md, status.Error(codes.Internal, "Internal server error")
+	}
+}
+
+// requestFromContext monta uma requisição HTTP com os metadados da chamada
+// como headers e o endereço do peer como RemoteAddr. Como no HTTP, os
+// metadados x-forwarded-for, x-real-ip e x-client-ip só são considerados se o
+// peer estiver em limiter.Config.TrustedProxies.
+func (i *interceptor) requestFromContext(ctx context.Context) *http.Request {
+	r := &http.Request{
+		Header: make(http.Header),
+		URL:    &url.URL{},
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for key, values := range md {
+			for _, value := range values {
+				r.Header.Add(key, value)
+			}
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		r.RemoteAddr = p.Addr.String()
+	}
+	return r.WithContext(ctx)
+}
+
+// resourceExhausted monta o erro de limite excedido, com RetryInfo e
+// QuotaFailure nos detalhes do status
+func resourceExhausted(result *limiter.LimitResult) error {
+	st := status.New(codes.ResourceExhausted, middleware.DefaultRejectionMessage)
+	detailed, err := st.WithDetails(
+		&errdetails.RetryInfo{
+			RetryDelay: durationpb.New(time.Duration(retryAfterSeconds(result.RetryAfter)) * time.Second),
+		},
+		&errdetails.QuotaFailure{
+			Violations: []*errdetails.QuotaFailure_Violation{{
+				Subject:     result.Rule,
+				Description: fmt.Sprintf("limit of %d requests per %s exceeded", result.Limit, result.Window),
+			}},
+		},
+	)
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+// retryAfterSeconds arredonda para cima, com mínimo de 1 segundo, como o
+// header Retry-After do middleware HTTP
+func retryAfterSeconds(d time.Duration) int64 {
+	seconds := int64((d + time.Second - 1) / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	return seconds
+}
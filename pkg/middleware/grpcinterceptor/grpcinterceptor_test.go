@@ -0,0 +1,215 @@
+package grpcinterceptor
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/m4rcelotoledo/rate-limiter/internal/limitertest"
+	"github.com/m4rcelotoledo/rate-limiter/pkg/limiter"
+	"github.com/m4rcelotoledo/rate-limiter/pkg/middleware"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// peerListener faz as conexões aceitas pelo bufconn parecerem vir de um
+// proxy em 10.0.0.1, já que o bufconn não informa um endereço IP
+type peerListener struct {
+	*bufconn.Listener
+}
+
+func (l peerListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return peerConn{conn}, nil
+}
+
+type peerConn struct {
+	net.Conn
+}
+
+func (peerConn) RemoteAddr() net.Addr {
+	return &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 50000}
+}
+
+// trustedProxy confia nos metadados de encaminhamento do peer de peerListener
+var trustedProxy = limiter.WithTrustedProxies(netip.MustParsePrefix("10.0.0.0/8"))
+
+// newTestClient sobe um servidor gRPC em memória (bufconn) com o serviço de
+// health check, que oferece uma chamada unária (Check) e uma stream (Watch)
+func newTestClient(t *testing.T, rateLimiter *limiter.RateLimiter, opts ...Option) healthpb.HealthClient {
+	t.Helper()
+
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(UnaryServerInterceptor(rateLimiter, opts...)),
+		grpc.StreamInterceptor(StreamServerInterceptor(rateLimiter, opts...)),
+	)
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	go server.Serve(peerListener{listener})
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("grpc.NewClient() error = %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return healthpb.NewHealthClient(conn)
+}
+
+func outgoingContext(t *testing.T, pairs ...string) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	return metadata.AppendToOutgoingContext(ctx, pairs...)
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	client := newTestClient(t, limitertest.NewRateLimiter(t, limiter.WithTokenHeaders("API_KEY", "Authorization"), trustedProxy))
+
+	tests := []struct {
+		name         string
+		metadata     []string
+		expectedCode codes.Code
+	}{
+		{name: "IP within limit", metadata: []string{"x-forwarded-for", "192.168.1.1"}, expectedCode: codes.OK},
+		{name: "IP at limit", metadata: []string{"x-forwarded-for", "192.168.1.1"}, expectedCode: codes.OK},
+		{name: "IP over limit", metadata: []string{"x-forwarded-for", "192.168.1.1"}, expectedCode: codes.ResourceExhausted},
+		{name: "Token in metadata", metadata: []string{"x-forwarded-for", "192.168.1.1", "api_key", "live-key"}, expectedCode: codes.OK},
+		{name: "Bearer token in metadata", metadata: []string{"x-forwarded-for", "192.168.1.1", "authorization", "Bearer live-key"}, expectedCode: codes.OK},
+		{name: "Denied IP", metadata: []string{"x-forwarded-for", "203.0.113.9"}, expectedCode: codes.PermissionDenied},
+		{name: "Allowed token is exempt", metadata: []string{"x-forwarded-for", "192.168.1.1", "api_key", "internal-key"}, expectedCode: codes.OK},
+		{name: "Unknown token", metadata: []string{"x-forwarded-for", "192.168.1.2", "api_key", "unknown-key"}, expectedCode: codes.Unauthenticated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.Check(outgoingContext(t, tt.metadata...), &healthpb.HealthCheckRequest{})
+			if code := status.Code(err); code != tt.expectedCode {
+				t.Errorf("Check() code = %v, expected %v (err: %v)", code, tt.expectedCode, err)
+			}
+		})
+	}
+}
+
+func TestUnaryServerInterceptor_RetryInfo(t *testing.T) {
+	client := newTestClient(t, limitertest.NewRateLimiter(t, limiter.WithTokenHeaders("API_KEY", "Authorization"), trustedProxy))
+	ctx := outgoingContext(t, "x-forwarded-for", "198.51.100.7")
+
+	var header metadata.MD
+	var err error
+	for i := 0; i < 3; i++ {
+		_, err = client.Check(ctx, &healthpb.HealthCheckRequest{}, grpc.Header(&header))
+	}
+
+	st := status.Convert(err)
+	if st.Code() != codes.ResourceExhausted {
+		t.Fatalf("Check() code = %v, expected %v", st.Code(), codes.ResourceExhausted)
+	}
+
+	var retryInfo *errdetails.RetryInfo
+	var quotaFailure *errdetails.QuotaFailure
+	for _, detail := range st.Details() {
+		switch d := detail.(type) {
+		case *errdetails.RetryInfo:
+			retryInfo = d
+		case *errdetails.QuotaFailure:
+			quotaFailure = d
+		}
+	}
+
+	if retryInfo == nil {
+		t.Fatalf("status details missing RetryInfo")
+	}
+	if delay := retryInfo.RetryDelay.AsDuration(); delay != 60*time.Second {
+		t.Errorf("RetryDelay = %v, expected %v", delay, 60*time.Second)
+	}
+	if quotaFailure == nil || len(quotaFailure.Violations) != 1 || quotaFailure.Violations[0].Subject != "ip" {
+		t.Errorf("QuotaFailure = %v, expected a violation for rule %q", quotaFailure, "ip")
+	}
+
+	if got := header.Get("retry-after"); len(got) != 1 || got[0] != "60" {
+		t.Errorf("retry-after metadata = %v, expected [60]", got)
+	}
+	if got := header.Get("x-ratelimit-limit"); len(got) != 1 || got[0] != "2" {
+		t.Errorf("x-ratelimit-limit metadata = %v, expected [2]", got)
+	}
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	client := newTestClient(t, limitertest.NewRateLimiter(t, limiter.WithTokenHeaders("API_KEY", "Authorization"), trustedProxy))
+
+	openStream := func() (metadata.MD, error) {
+		stream, err := client.Watch(outgoingContext(t, "x-forwarded-for", "192.168.1.1"), &healthpb.HealthCheckRequest{})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := stream.Recv(); err != nil {
+			return nil, err
+		}
+		return stream.Header()
+	}
+
+	for i := 0; i < 2; i++ {
+		header, err := openStream()
+		if err != nil {
+			t.Fatalf("stream %d error = %v", i+1, err)
+		}
+		if got := header.Get("x-ratelimit-limit"); len(got) != 1 || got[0] != "2" {
+			t.Errorf("x-ratelimit-limit metadata = %v, expected [2]", got)
+		}
+	}
+
+	if _, err := openStream(); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("stream over limit code = %v, expected %v", status.Code(err), codes.ResourceExhausted)
+	}
+}
+
+func TestUnaryServerInterceptor_UntrustedForwardingMetadata(t *testing.T) {
+	client := newTestClient(t, limitertest.NewRateLimiter(t, limiter.WithTokenHeaders("API_KEY", "Authorization")))
+
+	// Sem proxy confiável, o IP informado pelo cliente é ignorado: a denylist
+	// não se aplica, e trocar de IP não escapa do limite do peer
+	for i, ip := range []string{"203.0.113.9", "192.168.1.1", "192.168.1.2"} {
+		_, err := client.Check(outgoingContext(t, "x-forwarded-for", ip), &healthpb.HealthCheckRequest{})
+		expected := codes.OK
+		if i == 2 {
+			expected = codes.ResourceExhausted
+		}
+		if code := status.Code(err); code != expected {
+			t.Errorf("Check() with x-forwarded-for %s code = %v, expected %v", ip, code, expected)
+		}
+	}
+}
+
+func TestUnaryServerInterceptor_MiddlewareOptions(t *testing.T) {
+	client := newTestClient(t, limitertest.NewRateLimiter(t, limiter.WithTokenHeaders("API_KEY", "Authorization"), trustedProxy), WithMiddlewareOptions(middleware.WithHeaders(middleware.IETFHeaders)))
+
+	var header metadata.MD
+	if _, err := client.Check(outgoingContext(t, "x-forwarded-for", "192.168.1.1"), &healthpb.HealthCheckRequest{}, grpc.Header(&header)); err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if got := header.Get("ratelimit-policy"); len(got) != 1 || got[0] != `"ip";q=2;w=1` {
+		t.Errorf("ratelimit-policy metadata = %v, expected [\"ip\";q=2;w=1]", got)
+	}
+	if got := header.Get("x-ratelimit-limit"); len(got) != 0 {
+		t.Errorf("x-ratelimit-limit metadata = %v, expected none with IETF headers", got)
+	}
+}
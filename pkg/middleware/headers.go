@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/m4rcelotoledo/rate-limiter/pkg/limiter"
+)
+
+// HeaderMode define quais headers de rate limit são enviados nas respostas
+type HeaderMode int
+
+const (
+	// LegacyHeaders envia X-RateLimit-Limit, X-RateLimit-Remaining e
+	// X-RateLimit-Reset (timestamp RFC3339)
+	LegacyHeaders HeaderMode = 1 << iota
+	// IETFHeaders envia RateLimit e RateLimit-Policy conforme o draft
+	// draft-ietf-httpapi-ratelimit-headers (reset em segundos)
+	IETFHeaders
+
+	// AllHeaders envia os headers legados e os do draft IETF
+	AllHeaders = LegacyHeaders | IETFHeaders
+)
+
+// ParseHeaderMode converte "legacy", "ietf" ou "both" em HeaderMode
+func ParseHeaderMode(value string) (HeaderMode, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "legacy":
+		return LegacyHeaders, nil
+	case "ietf":
+		return IETFHeaders, nil
+	case "both", "all":
+		return AllHeaders, nil
+	default:
+		return 0, fmt.Errorf("invalid rate limit header mode: %s", value)
+	}
+}
+
+// setRateLimitHeaders adiciona os headers de rate limit do resultado
+func setRateLimitHeaders(header http.Header, result *limiter.LimitResult, mode HeaderMode) {
+	if mode&LegacyHeaders != 0 {
+		header.Set("X-RateLimit-Limit", fmt.Sprintf("%d", result.Limit))
+		header.Set("X-RateLimit-Remaining", fmt.Sprintf("%d", result.Remaining))
+		header.Set("X-RateLimit-Reset", result.ResetTime.Format(time.RFC3339))
+	}
+
+	if mode&IETFHeaders != 0 {
+		policy := result.Rule
+		if policy == "" {
+			policy = "default"
+		}
+		header.Set("RateLimit-Policy", fmt.Sprintf("%q;q=%d;w=%d", policy, result.Limit, deltaSeconds(result.Window)))
+		header.Set("RateLimit", fmt.Sprintf("%q;r=%d;t=%d", policy, result.Remaining, deltaSeconds(time.Until(result.ResetTime))))
+	}
+}
+
+// setRetryAfterHeader informa em Retry-After quantos segundos faltam para o
+// bloqueio expirar (mínimo de 1 segundo)
+func setRetryAfterHeader(header http.Header, result *limiter.LimitResult) {
+	retryAfter := deltaSeconds(result.RetryAfter)
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+	header.Set("Retry-After", strconv.FormatInt(retryAfter, 10))
+}
+
+// setShadowHeader informa em X-RateLimit-Shadow a regra cujo limite candidato
+// em shadow mode teria negado a requisição
+func setShadowHeader(header http.Header, result *limiter.LimitResult) {
+	header.Set("X-RateLimit-Shadow", result.Rule)
+}
+
+// deltaSeconds arredonda a duração para cima em segundos, sem valores negativos
+func deltaSeconds(d time.Duration) int64 {
+	if d <= 0 {
+		return 0
+	}
+	return int64(math.Ceil(d.Seconds()))
+}
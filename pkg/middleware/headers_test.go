@@ -0,0 +1,129 @@
+package middleware
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/m4rcelotoledo/rate-limiter/pkg/limiter"
+)
+
+func TestSetRateLimitHeaders(t *testing.T) {
+	result := &limiter.LimitResult{
+		Allowed:   true,
+		Limit:     100,
+		Remaining: 42,
+		ResetTime: time.Now().Add(1500 * time.Millisecond),
+		Window:    time.Second,
+		Rule:      "token:gold",
+	}
+
+	tests := []struct {
+		name     string
+		mode     HeaderMode
+		expected map[string]string
+		absent   []string
+	}{
+		{
+			name: "Legacy headers",
+			mode: LegacyHeaders,
+			expected: map[string]string{
+				"X-RateLimit-Limit":     "100",
+				"X-RateLimit-Remaining": "42",
+				"X-RateLimit-Reset":     result.ResetTime.Format(time.RFC3339),
+			},
+			absent: []string{"RateLimit", "RateLimit-Policy"},
+		},
+		{
+			name: "IETF headers",
+			mode: IETFHeaders,
+			expected: map[string]string{
+				"RateLimit-Policy": `"token:gold";q=100;w=1`,
+				"RateLimit":        `"token:gold";r=42;t=2`,
+			},
+			absent: []string{"X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset"},
+		},
+		{
+			name: "All headers",
+			mode: AllHeaders,
+			expected: map[string]string{
+				"X-RateLimit-Limit": "100",
+				"RateLimit-Policy":  `"token:gold";q=100;w=1`,
+				"RateLimit":         `"token:gold";r=42;t=2`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			setRateLimitHeaders(header, result, tt.mode)
+
+			for name, value := range tt.expected {
+				if got := header.Get(name); got != value {
+					t.Errorf("header %s = %q, expected %q", name, got, value)
+				}
+			}
+			for _, name := range tt.absent {
+				if got := header.Get(name); got != "" {
+					t.Errorf("header %s = %q, expected no header", name, got)
+				}
+			}
+		})
+	}
+}
+
+func TestSetRetryAfterHeader(t *testing.T) {
+	tests := []struct {
+		name       string
+		retryAfter time.Duration
+		expected   string
+	}{
+		{name: "Whole seconds", retryAfter: 300 * time.Second, expected: "300"},
+		{name: "Rounded up", retryAfter: 12300 * time.Millisecond, expected: "13"},
+		{name: "Minimum of one second", retryAfter: 0, expected: "1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			setRetryAfterHeader(header, &limiter.LimitResult{RetryAfter: tt.retryAfter})
+
+			if got := header.Get("Retry-After"); got != tt.expected {
+				t.Errorf("Retry-After = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseHeaderMode(t *testing.T) {
+	tests := []struct {
+		value       string
+		expected    HeaderMode
+		expectError bool
+	}{
+		{value: "", expected: LegacyHeaders},
+		{value: "legacy", expected: LegacyHeaders},
+		{value: "IETF", expected: IETFHeaders},
+		{value: "both", expected: AllHeaders},
+		{value: "draft", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			mode, err := ParseHeaderMode(tt.value)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("ParseHeaderMode(%q) expected error", tt.value)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseHeaderMode(%q) error = %v", tt.value, err)
+			}
+			if mode != tt.expected {
+				t.Errorf("ParseHeaderMode(%q) = %v, expected %v", tt.value, mode, tt.expected)
+			}
+		})
+	}
+}
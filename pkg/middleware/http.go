@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/m4rcelotoledo/rate-limiter/pkg/limiter"
+)
+
+// RateLimiterHandler é o equivalente de RateLimiterMiddleware para net/http
+// (e roteadores compatíveis, como chi), com a mesma decisão e os mesmos headers
+func RateLimiterHandler(rateLimiter *limiter.RateLimiter, opts ...Option) func(http.Handler) http.Handler {
+	decider := NewDecider(rateLimiter, opts...)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if rejection := decider.Decide(r, w.Header()); rejection != nil {
+				w.Header().Set("Content-Type", rejection.ContentType)
+				w.WriteHeader(rejection.StatusCode)
+				w.Write(rejection.Body)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,241 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/m4rcelotoledo/rate-limiter/internal/limitertest"
+	"github.com/m4rcelotoledo/rate-limiter/pkg/access"
+	"github.com/m4rcelotoledo/rate-limiter/pkg/limiter"
+	"github.com/m4rcelotoledo/rate-limiter/pkg/storage"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestRateLimiterHandler_MatchesGinMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	ginRouter := gin.New()
+	ginRouter.Use(RateLimiterMiddleware(limitertest.NewRateLimiter(t), WithHeaders(AllHeaders)))
+	ginRouter.GET("/", gin.WrapF(ok))
+
+	httpHandler := RateLimiterHandler(limitertest.NewRateLimiter(t), WithHeaders(AllHeaders))(ok)
+
+	requests := []struct {
+		name           string
+		remoteAddr     string
+		token          string
+		expectedStatus int
+	}{
+		{name: "IP within limit", remoteAddr: "192.168.1.1:1234", expectedStatus: http.StatusOK},
+		{name: "IP at limit", remoteAddr: "192.168.1.1:1234", expectedStatus: http.StatusOK},
+		{name: "IP over limit", remoteAddr: "192.168.1.1:1234", expectedStatus: http.StatusTooManyRequests},
+		{name: "IP still blocked", remoteAddr: "192.168.1.1:1234", expectedStatus: http.StatusTooManyRequests},
+		{name: "Token has its own limit", remoteAddr: "192.168.1.1:1234", token: "live-key", expectedStatus: http.StatusOK},
+		{name: "Denied IP", remoteAddr: "203.0.113.9:1234", expectedStatus: http.StatusForbidden},
+		{name: "Allowed token bypasses block", remoteAddr: "192.168.1.1:1234", token: "internal-key", expectedStatus: http.StatusOK},
+		{name: "Unknown token", remoteAddr: "192.168.1.2:1234", token: "unknown-key", expectedStatus: http.StatusUnauthorized},
+	}
+
+	headers := []string{
+		"Content-Type",
+		"X-RateLimit-Limit",
+		"X-RateLimit-Remaining",
+		"RateLimit-Policy",
+		"Retry-After",
+	}
+
+	for _, tt := range requests {
+		t.Run(tt.name, func(t *testing.T) {
+			newRequest := func() *http.Request {
+				req := httptest.NewRequest(http.MethodGet, "/", nil)
+				req.RemoteAddr = tt.remoteAddr
+				if tt.token != "" {
+					req.Header.Set("API_KEY", tt.token)
+				}
+				return req
+			}
+
+			ginRecorder := httptest.NewRecorder()
+			ginRouter.ServeHTTP(ginRecorder, newRequest())
+
+			httpRecorder := httptest.NewRecorder()
+			httpHandler.ServeHTTP(httpRecorder, newRequest())
+
+			if httpRecorder.Code != tt.expectedStatus {
+				t.Errorf("net/http status = %d, expected %d", httpRecorder.Code, tt.expectedStatus)
+			}
+			if ginRecorder.Code != httpRecorder.Code {
+				t.Errorf("gin status = %d, net/http status = %d", ginRecorder.Code, httpRecorder.Code)
+			}
+			if ginRecorder.Body.String() != httpRecorder.Body.String() {
+				t.Errorf("gin body = %q, net/http body = %q", ginRecorder.Body.String(), httpRecorder.Body.String())
+			}
+			for _, header := range headers {
+				if ginValue, httpValue := ginRecorder.Header().Get(header), httpRecorder.Header().Get(header); ginValue != httpValue {
+					t.Errorf("%s: gin = %q, net/http = %q", header, ginValue, httpValue)
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimiterHandler_CallsNextHandler(t *testing.T) {
+	called := false
+	handler := RateLimiterHandler(limitertest.NewRateLimiter(t))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "192.168.1.1:1234"
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if !called {
+		t.Fatalf("next handler was not called")
+	}
+	if w.Code != http.StatusNoContent {
+		t.Errorf("status = %d, expected %d", w.Code, http.StatusNoContent)
+	}
+	if w.Header().Get("X-RateLimit-Limit") != "2" {
+		t.Errorf("X-RateLimit-Limit = %q, expected %q", w.Header().Get("X-RateLimit-Limit"), "2")
+	}
+}
+
+func TestRateLimiterHandler_SpoofedForwardingHeaders(t *testing.T) {
+	// Um cliente fora dos proxies confiáveis não entra na allowlist nem troca
+	// de IP informando X-Forwarded-For
+	list := access.NewList()
+	list.Add(access.Allow, access.Rules{IPs: []string{"10.0.0.0/8"}})
+	rateLimiter := limiter.New(storage.NewMemoryStorage(),
+		limiter.WithIPLimit(2, 60),
+		limiter.WithAccessList(list),
+		limiter.WithTrustedProxies(netip.MustParsePrefix("192.0.2.0/24")),
+	)
+	handler := RateLimiterHandler(rateLimiter)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	for i, forwardedFor := range []string{"10.1.2.3", "10.1.2.3", "10.1.2.3", "192.168.1.1", "192.168.1.2"} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = "198.51.100.7:1234"
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		expected := http.StatusOK
+		if i >= 2 {
+			expected = http.StatusTooManyRequests
+		}
+		if w.Code != expected {
+			t.Errorf("request %d with X-Forwarded-For %s: status = %d, expected %d", i+1, forwardedFor, w.Code, expected)
+		}
+	}
+
+	// Através de um proxy confiável, o IP encaminhado é usado
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Forwarded-For", "10.1.2.3")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("allowlisted IP through a trusted proxy: status = %d, expected %d", w.Code, http.StatusOK)
+	}
+}
+
+func TestRateLimiterHandler_Tracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	rateLimiter := limiter.New(storage.NewMemoryStorage(), limiter.WithIPLimit(1, 60), limiter.WithTracerProvider(provider))
+	handler := RateLimiterHandler(rateLimiter, WithTracerProvider(provider))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = "192.168.1.1:1234"
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	var decides, checks []tracetest.SpanStub
+	for _, span := range exporter.GetSpans() {
+		switch span.Name {
+		case "rate_limiter.decide":
+			decides = append(decides, span)
+		case "rate_limiter.check_limit":
+			checks = append(checks, span)
+		}
+	}
+	if len(decides) != 2 || len(checks) != 2 {
+		t.Fatalf("got %d decide and %d check_limit spans, expected 2 of each", len(decides), len(checks))
+	}
+
+	for i := range decides {
+		if checks[i].Parent.SpanID() != decides[i].SpanContext.SpanID() {
+			t.Errorf("check_limit span %d is not a child of decide", i)
+		}
+	}
+
+	denied := make(map[attribute.Key]attribute.Value)
+	for _, kv := range decides[1].Attributes {
+		denied[kv.Key] = kv.Value
+	}
+	if denied["rate_limiter.allowed"].AsBool() {
+		t.Errorf("allowed = true, expected false")
+	}
+	if denied["http.response.status_code"].AsInt64() != http.StatusTooManyRequests {
+		t.Errorf("status code = %d, expected %d", denied["http.response.status_code"].AsInt64(), http.StatusTooManyRequests)
+	}
+	if denied["rate_limiter.limit_type"].AsString() != "ip" {
+		t.Errorf("limit_type = %q, expected %q", denied["rate_limiter.limit_type"].AsString(), "ip")
+	}
+}
+
+func TestRateLimiterHandler_ShadowMode(t *testing.T) {
+	rateLimiter := limiter.New(storage.NewMemoryStorage(),
+		limiter.WithIPLimit(2, 60),
+		limiter.WithShadowRule("ip", limiter.Tier{RequestsPerSecond: 1, BlockDurationSeconds: 60}),
+	)
+	handler := RateLimiterHandler(rateLimiter)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	// O limite atual continua aplicado e os headers normais são enviados;
+	// X-RateLimit-Shadow sinaliza as requisições que o candidato negaria
+	expected := []struct {
+		status    int
+		remaining string
+		shadow    string
+	}{
+		{status: http.StatusOK, remaining: "1"},
+		{status: http.StatusOK, remaining: "0", shadow: "ip"},
+		{status: http.StatusTooManyRequests, remaining: "0", shadow: "ip"},
+	}
+	for i, want := range expected {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = "192.168.1.1:1234"
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if w.Code != want.status {
+			t.Errorf("request %d: status = %d, expected %d", i+1, w.Code, want.status)
+		}
+		if got := w.Header().Get("X-RateLimit-Shadow"); got != want.shadow {
+			t.Errorf("request %d: X-RateLimit-Shadow = %q, expected %q", i+1, got, want.shadow)
+		}
+		if got := w.Header().Get("X-RateLimit-Limit"); got != "2" {
+			t.Errorf("request %d: X-RateLimit-Limit = %q, expected %q", i+1, got, "2")
+		}
+		if got := w.Header().Get("X-RateLimit-Remaining"); got != want.remaining {
+			t.Errorf("request %d: X-RateLimit-Remaining = %q, expected %q", i+1, got, want.remaining)
+		}
+	}
+}
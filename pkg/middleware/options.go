@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Option configura o comportamento do middleware de rate limiting
+type Option func(*options)
+
+type options struct {
+	headers          HeaderMode
+	rejectionHandler RejectionHandler
+	tracerProvider   trace.TracerProvider
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		headers:          LegacyHeaders,
+		rejectionHandler: defaultRejectionHandler(),
+		tracerProvider:   otel.GetTracerProvider(),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithHeaders define quais headers de rate limit são enviados (padrão: LegacyHeaders)
+func WithHeaders(mode HeaderMode) Option {
+	return func(o *options) {
+		o.headers = mode
+	}
+}
+
+// WithRejectionHandler define a resposta enviada quando o limite é excedido
+// (padrão: JSON com status 429). Ver NewRejectionHandler.
+func WithRejectionHandler(handler RejectionHandler) Option {
+	return func(o *options) {
+		o.rejectionHandler = handler
+	}
+}
+
+// WithTracerProvider define o provider do span OpenTelemetry criado para cada
+// requisição (padrão: provider global)
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(o *options) {
+		o.tracerProvider = provider
+	}
+}
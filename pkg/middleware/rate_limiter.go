@@ -0,0 +1,23 @@
+// Package middleware integra o rate limiter a servidores HTTP (Gin e net/http).
+// Adaptadores para Echo, Fiber e gRPC estão nos subpacotes.
+package middleware
+
+import (
+	"github.com/m4rcelotoledo/rate-limiter/pkg/limiter"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RateLimiterMiddleware(rateLimiter *limiter.RateLimiter, opts ...Option) gin.HandlerFunc {
+	decider := NewDecider(rateLimiter, opts...)
+
+	return func(c *gin.Context) {
+		if rejection := decider.Decide(c.Request, c.Writer.Header()); rejection != nil {
+			c.Data(rejection.StatusCode, rejection.ContentType, rejection.Body)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
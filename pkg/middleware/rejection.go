@@ -0,0 +1,287 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/m4rcelotoledo/rate-limiter/pkg/limiter"
+)
+
+// DefaultRejectionMessage é a mensagem enviada quando nenhuma tradução se aplica
+const DefaultRejectionMessage = "you have reached the maximum number of requests or actions allowed within a certain time frame"
+
+// Rejection é a resposta enviada quando a requisição excede o limite
+type Rejection struct {
+	StatusCode  int
+	ContentType string
+	Body        []byte
+}
+
+// RejectionHandler monta a resposta para uma requisição que excedeu o limite
+type RejectionHandler func(r *http.Request, result *limiter.LimitResult) Rejection
+
+// RejectionFormat define o formato do corpo da resposta de rejeição
+type RejectionFormat string
+
+const (
+	// JSONFormat envia {"error": "<mensagem>"}
+	JSONFormat RejectionFormat = "json"
+	// ProblemFormat envia application/problem+json conforme a RFC 9457
+	ProblemFormat RejectionFormat = "problem"
+	// TextFormat envia a mensagem em texto puro
+	TextFormat RejectionFormat = "text"
+	// HTMLFormat envia uma página HTML com a mensagem
+	HTMLFormat RejectionFormat = "html"
+)
+
+// RejectionConfig configura o RejectionHandler padrão
+type RejectionConfig struct {
+	// Format é o formato do corpo (padrão: JSONFormat)
+	Format RejectionFormat
+	// StatusCode é o status HTTP da rejeição (padrão: 429)
+	StatusCode int
+	// RuleStatusCodes substitui o status para regras específicas
+	// (ex: {"token:free": 503})
+	RuleStatusCodes map[string]int
+	// Messages contém a mensagem por idioma (ex: "en", "pt-BR"), escolhida
+	// pelo header Accept-Language
+	Messages map[string]string
+	// DefaultLanguage é o idioma usado quando Accept-Language não corresponde
+	// a nenhuma mensagem (padrão: en)
+	DefaultLanguage string
+	// Template substitui o corpo nos formatos text e html. Recebe os campos de
+	// RejectionData (ex: {{.Message}}, {{.RetryAfter}}).
+	Template string
+}
+
+// RejectionData são os campos disponíveis para os templates de rejeição
+type RejectionData struct {
+	Message    string
+	StatusCode int
+	Limit      int64
+	Remaining  int64
+	RetryAfter int64
+	ResetTime  time.Time
+	Rule       string
+}
+
+// ValidRejectionStatus indica se o status pode ser usado numa rejeição: um
+// erro do cliente ou do servidor (400-599)
+func ValidRejectionStatus(statusCode int) bool {
+	return statusCode >= 400 && statusCode <= 599
+}
+
+// NewRejectionHandler cria o RejectionHandler padrão a partir da configuração
+func NewRejectionHandler(config RejectionConfig) (RejectionHandler, error) {
+	if config.Format == "" {
+		config.Format = JSONFormat
+	}
+	if config.StatusCode == 0 {
+		config.StatusCode = http.StatusTooManyRequests
+	}
+	if config.DefaultLanguage == "" {
+		config.DefaultLanguage = "en"
+	}
+	if !ValidRejectionStatus(config.StatusCode) {
+		return nil, fmt.Errorf("invalid rejection status code: %d (expected 400-599)", config.StatusCode)
+	}
+	for rule, statusCode := range config.RuleStatusCodes {
+		if !ValidRejectionStatus(statusCode) {
+			return nil, fmt.Errorf("invalid rejection status code for rule %q: %d (expected 400-599)", rule, statusCode)
+		}
+	}
+
+	var render func(data RejectionData) ([]byte, error)
+	switch config.Format {
+	case JSONFormat, ProblemFormat:
+	case TextFormat:
+		if config.Template != "" {
+			tmpl, err := texttemplate.New("rejection").Parse(config.Template)
+			if err != nil {
+				return nil, fmt.Errorf("invalid rejection template: %w", err)
+			}
+			render = func(data RejectionData) ([]byte, error) {
+				var body bytes.Buffer
+				err := tmpl.Execute(&body, data)
+				return body.Bytes(), err
+			}
+		}
+	case HTMLFormat:
+		source := config.Template
+		if source == "" {
+			source = defaultHTMLTemplate
+		}
+		tmpl, err := htmltemplate.New("rejection").Parse(source)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rejection template: %w", err)
+		}
+		render = func(data RejectionData) ([]byte, error) {
+			var body bytes.Buffer
+			err := tmpl.Execute(&body, data)
+			return body.Bytes(), err
+		}
+	default:
+		return nil, fmt.Errorf("invalid rejection format: %s", config.Format)
+	}
+
+	return func(r *http.Request, result *limiter.LimitResult) Rejection {
+		statusCode := config.StatusCode
+		if ruleStatus, ok := config.RuleStatusCodes[result.Rule]; ok {
+			statusCode = ruleStatus
+		}
+
+		data := RejectionData{
+			Message:    selectMessage(config.Messages, config.DefaultLanguage, r.Header.Get("Accept-Language")),
+			StatusCode: statusCode,
+			Limit:      result.Limit,
+			Remaining:  result.Remaining,
+			RetryAfter: deltaSeconds(result.RetryAfter),
+			ResetTime:  result.ResetTime,
+			Rule:       result.Rule,
+		}
+
+		switch config.Format {
+		case ProblemFormat:
+			return jsonRejection(statusCode, "application/problem+json", map[string]interface{}{
+				"type":        "about:blank",
+				"title":       http.StatusText(statusCode),
+				"status":      statusCode,
+				"detail":      data.Message,
+				"instance":    r.URL.Path,
+				"limit":       data.Limit,
+				"retry_after": data.RetryAfter,
+			})
+		case TextFormat:
+			if render == nil {
+				return Rejection{StatusCode: statusCode, ContentType: "text/plain; charset=utf-8", Body: []byte(data.Message)}
+			}
+			return renderedRejection(statusCode, "text/plain; charset=utf-8", data, render)
+		case HTMLFormat:
+			return renderedRejection(statusCode, "text/html; charset=utf-8", data, render)
+		default:
+			return jsonRejection(statusCode, "application/json; charset=utf-8", map[string]string{
+				"error": data.Message,
+			})
+		}
+	}, nil
+}
+
+// defaultRejectionHandler mantém a resposta JSON original com status 429
+func defaultRejectionHandler() RejectionHandler {
+	handler, _ := NewRejectionHandler(RejectionConfig{})
+	return handler
+}
+
+func jsonRejection(statusCode int, contentType string, body interface{}) Rejection {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return Rejection{StatusCode: statusCode, ContentType: "text/plain; charset=utf-8", Body: []byte(DefaultRejectionMessage)}
+	}
+	return Rejection{StatusCode: statusCode, ContentType: contentType, Body: data}
+}
+
+func renderedRejection(statusCode int, contentType string, data RejectionData, render func(RejectionData) ([]byte, error)) Rejection {
+	body, err := render(data)
+	if err != nil {
+		return Rejection{StatusCode: statusCode, ContentType: "text/plain; charset=utf-8", Body: []byte(data.Message)}
+	}
+	return Rejection{StatusCode: statusCode, ContentType: contentType, Body: body}
+}
+
+// selectMessage escolhe a mensagem pelo Accept-Language, considerando os
+// pesos q e correspondências pelo idioma base (ex: "pt" para "pt-BR")
+func selectMessage(messages map[string]string, defaultLanguage string, acceptLanguage string) string {
+	for _, language := range parseAcceptLanguage(acceptLanguage) {
+		if message, ok := lookupMessage(messages, language); ok {
+			return message
+		}
+	}
+	if message, ok := lookupMessage(messages, defaultLanguage); ok {
+		return message
+	}
+	return DefaultRejectionMessage
+}
+
+func lookupMessage(messages map[string]string, language string) (string, bool) {
+	if language == "" {
+		return "", false
+	}
+
+	keys := make([]string, 0, len(messages))
+	for key := range messages {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	base := baseLanguage(language)
+	var baseMatch string
+	for _, key := range keys {
+		if strings.EqualFold(key, language) {
+			return messages[key], true
+		}
+		if baseMatch == "" && strings.EqualFold(baseLanguage(key), base) {
+			baseMatch = messages[key]
+		}
+	}
+	return baseMatch, baseMatch != ""
+}
+
+func baseLanguage(language string) string {
+	base, _, _ := strings.Cut(language, "-")
+	return base
+}
+
+// parseAcceptLanguage retorna os idiomas do header ordenados pelo peso q
+func parseAcceptLanguage(header string) []string {
+	type weighted struct {
+		language string
+		quality  float64
+	}
+
+	var languages []weighted
+	for _, part := range strings.Split(header, ",") {
+		language, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if language == "" || language == "*" {
+			continue
+		}
+
+		quality := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+				quality = parsed
+			}
+		}
+		if quality > 0 {
+			languages = append(languages, weighted{language: language, quality: quality})
+		}
+	}
+
+	sort.SliceStable(languages, func(i, j int) bool {
+		return languages[i].quality > languages[j].quality
+	})
+
+	result := make([]string, len(languages))
+	for i, language := range languages {
+		result[i] = language.language
+	}
+	return result
+}
+
+const defaultHTMLTemplate = `<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>{{.StatusCode}}</title></head>
+<body>
+<h1>{{.StatusCode}}</h1>
+<p>{{.Message}}</p>
+{{if .RetryAfter}}<p>Retry after {{.RetryAfter}}s</p>{{end}}
+</body>
+</html>
+`
@@ -0,0 +1,164 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/m4rcelotoledo/rate-limiter/pkg/limiter"
+)
+
+func TestNewRejectionHandler(t *testing.T) {
+	result := &limiter.LimitResult{
+		Limit:      10,
+		Remaining:  0,
+		RetryAfter: 30 * time.Second,
+		Rule:       "ip",
+	}
+
+	tests := []struct {
+		name                string
+		config              RejectionConfig
+		rule                string
+		acceptLanguage      string
+		expectedStatus      int
+		expectedContentType string
+		expectedBody        []string
+	}{
+		{
+			name:                "Default JSON",
+			config:              RejectionConfig{},
+			expectedStatus:      http.StatusTooManyRequests,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        []string{`{"error":"` + DefaultRejectionMessage + `"}`},
+		},
+		{
+			name:                "Problem details",
+			config:              RejectionConfig{Format: ProblemFormat},
+			expectedStatus:      http.StatusTooManyRequests,
+			expectedContentType: "application/problem+json",
+			expectedBody:        []string{`"title":"Too Many Requests"`, `"status":429`, `"instance":"/test"`, `"retry_after":30`},
+		},
+		{
+			name:                "Plain text",
+			config:              RejectionConfig{Format: TextFormat},
+			expectedStatus:      http.StatusTooManyRequests,
+			expectedContentType: "text/plain; charset=utf-8",
+			expectedBody:        []string{DefaultRejectionMessage},
+		},
+		{
+			name:                "Plain text template",
+			config:              RejectionConfig{Format: TextFormat, Template: "slow down, retry in {{.RetryAfter}}s"},
+			expectedStatus:      http.StatusTooManyRequests,
+			expectedContentType: "text/plain; charset=utf-8",
+			expectedBody:        []string{"slow down, retry in 30s"},
+		},
+		{
+			name:                "HTML escapes the message",
+			config:              RejectionConfig{Format: HTMLFormat, Messages: map[string]string{"en": "<b>too many</b>"}},
+			expectedStatus:      http.StatusTooManyRequests,
+			expectedContentType: "text/html; charset=utf-8",
+			expectedBody:        []string{"&lt;b&gt;too many&lt;/b&gt;", "Retry after 30s"},
+		},
+		{
+			name:                "Alternate status per rule",
+			config:              RejectionConfig{RuleStatusCodes: map[string]int{"ip": http.StatusServiceUnavailable}},
+			expectedStatus:      http.StatusServiceUnavailable,
+			expectedContentType: "application/json; charset=utf-8",
+		},
+		{
+			name:                "Rule without alternate status",
+			config:              RejectionConfig{RuleStatusCodes: map[string]int{"token": http.StatusServiceUnavailable}},
+			expectedStatus:      http.StatusTooManyRequests,
+			expectedContentType: "application/json; charset=utf-8",
+		},
+		{
+			name: "Localized message by Accept-Language",
+			config: RejectionConfig{Messages: map[string]string{
+				"en":    "too many requests",
+				"pt-BR": "muitas requisições",
+			}},
+			acceptLanguage:      "fr;q=0.9, pt-BR, en;q=0.8",
+			expectedStatus:      http.StatusTooManyRequests,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        []string{"muitas requisições"},
+		},
+		{
+			name: "Localized message by base language",
+			config: RejectionConfig{Messages: map[string]string{
+				"en":    "too many requests",
+				"pt-BR": "muitas requisições",
+			}},
+			acceptLanguage:      "pt",
+			expectedStatus:      http.StatusTooManyRequests,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        []string{"muitas requisições"},
+		},
+		{
+			name: "Default language when no match",
+			config: RejectionConfig{
+				Messages:        map[string]string{"en": "too many requests", "pt-BR": "muitas requisições"},
+				DefaultLanguage: "pt-BR",
+			},
+			acceptLanguage:      "de",
+			expectedStatus:      http.StatusTooManyRequests,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        []string{"muitas requisições"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, err := NewRejectionHandler(tt.config)
+			if err != nil {
+				t.Fatalf("NewRejectionHandler() error = %v", err)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			if tt.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", tt.acceptLanguage)
+			}
+
+			rejection := handler(req, result)
+			if rejection.StatusCode != tt.expectedStatus {
+				t.Errorf("status = %d, expected %d", rejection.StatusCode, tt.expectedStatus)
+			}
+			if rejection.ContentType != tt.expectedContentType {
+				t.Errorf("content type = %q, expected %q", rejection.ContentType, tt.expectedContentType)
+			}
+			for _, expected := range tt.expectedBody {
+				if !strings.Contains(string(rejection.Body), expected) {
+					t.Errorf("body = %s, expected to contain %s", rejection.Body, expected)
+				}
+			}
+			if strings.Contains(tt.expectedContentType, "json") {
+				if !json.Valid(rejection.Body) {
+					t.Errorf("body is not valid JSON: %s", rejection.Body)
+				}
+			}
+		})
+	}
+}
+
+func TestNewRejectionHandler_InvalidConfig(t *testing.T) {
+	if _, err := NewRejectionHandler(RejectionConfig{Format: "xml"}); err == nil {
+		t.Errorf("NewRejectionHandler() with invalid format should return an error")
+	}
+	if _, err := NewRejectionHandler(RejectionConfig{Format: HTMLFormat, Template: "{{.Message"}); err == nil {
+		t.Errorf("NewRejectionHandler() with invalid template should return an error")
+	}
+	for _, statusCode := range []int{42, 200, 302, 600, 1000} {
+		if _, err := NewRejectionHandler(RejectionConfig{StatusCode: statusCode}); err == nil {
+			t.Errorf("NewRejectionHandler() with status %d should return an error", statusCode)
+		}
+		if _, err := NewRejectionHandler(RejectionConfig{RuleStatusCodes: map[string]int{"token:free": statusCode}}); err == nil {
+			t.Errorf("NewRejectionHandler() with rule status %d should return an error", statusCode)
+		}
+	}
+	if _, err := NewRejectionHandler(RejectionConfig{StatusCode: 503, RuleStatusCodes: map[string]int{"ip": 403}}); err != nil {
+		t.Errorf("NewRejectionHandler() with valid status codes error = %v", err)
+	}
+}
@@ -0,0 +1,274 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultScanCount é o número de chaves por chamada de Scan quando count
+	// não é informado (mesmo padrão do Redis)
+	defaultScanCount = 10
+	// scanSnapshotTTL é o tempo após o qual iterações abandonadas são descartadas
+	scanSnapshotTTL = time.Minute
+	// cleanupInterval é o intervalo de remoção das chaves expiradas
+	cleanupInterval = time.Minute
+)
+
+// MemoryStorage é um StorageStrategy em memória, para instâncias únicas,
+// desenvolvimento e testes. Os limites não são compartilhados entre processos.
+type MemoryStorage struct {
+	mu        sync.Mutex
+	entries   map[string]memoryEntry
+	snapshots map[uint32]*scanSnapshot
+	// lastSnapshotID identifica a iteração mais recente (ver Scan)
+	lastSnapshotID uint32
+	done           chan struct{}
+	closeOnce      sync.Once
+}
+
+type memoryEntry struct {
+	value int64
+	// expiresAt é zero para chaves sem expiração
+	expiresAt time.Time
+}
+
+// scanSnapshot guarda as chaves de uma iteração de Scan
+type scanSnapshot struct {
+	keys      []string
+	createdAt time.Time
+}
+
+func (e memoryEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+// NewMemoryStorage cria o storage e inicia a remoção periódica das chaves
+// expiradas, encerrada por Close
+func NewMemoryStorage() *MemoryStorage {
+	m := &MemoryStorage{
+		entries:   make(map[string]memoryEntry),
+		snapshots: make(map[uint32]*scanSnapshot),
+		done:      make(chan struct{}),
+	}
+	go m.cleanup()
+	return m
+}
+
+func (m *MemoryStorage) cleanup() {
+	ticker := time.NewTicker(cleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.done:
+			return
+		case now := <-ticker.C:
+			m.mu.Lock()
+			for key, entry := range m.entries {
+				if entry.expired(now) {
+					delete(m.entries, key)
+				}
+			}
+			m.mu.Unlock()
+		}
+	}
+}
+
+// entry retorna a entrada da chave, removendo-a se tiver expirado. Deve ser
+// chamado com o mutex travado.
+func (m *MemoryStorage) entry(key string, now time.Time) (memoryEntry, bool) {
+	entry, ok := m.entries[key]
+	if ok && entry.expired(now) {
+		delete(m.entries, key)
+		return memoryEntry{}, false
+	}
+	return entry, ok
+}
+
+// Increment incrementa o contador e renova a expiração, como o
+// INCR + EXPIRE do RedisStorage
+func (m *MemoryStorage) Increment(ctx context.Context, key string, expiration time.Duration) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	entry, _ := m.entry(key, now)
+	entry.value++
+	entry.expiresAt = expiresAt(now, expiration)
+	m.entries[key] = entry
+	return entry.value, nil
+}
+
+func (m *MemoryStorage) Get(ctx context.Context, key string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, _ := m.entry(key, time.Now())
+	return entry.value, nil
+}
+
+// Set define o valor da chave; expiração zero mantém a chave sem expiração
+func (m *MemoryStorage) Set(ctx context.Context, key string, value int64, expiration time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	m.entries[key] = memoryEntry{value: value, expiresAt: expiresAt(now, expiration)}
+	return nil
+}
+
+func (m *MemoryStorage) Exists(ctx context.Context, key string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, ok := m.entry(key, time.Now())
+	return ok, nil
+}
+
+func (m *MemoryStorage) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.entries, key)
+	return nil
+}
+
+func (m *MemoryStorage) TTL(ctx context.Context, key string) (time.Duration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	entry, ok := m.entry(key, now)
+	if !ok || entry.expiresAt.IsZero() {
+		return 0, nil
+	}
+	return entry.expiresAt.Sub(now), nil
+}
+
+// Scan itera sobre um snapshot ordenado das chaves que correspondem ao
+// padrão, tirado na chamada com cursor 0. O cursor codifica o snapshot (32
+// bits mais altos) e a posição nele (32 bits mais baixos). Chaves removidas
+// depois do snapshot são omitidas; chaves criadas depois dele não são
+// retornadas. Iterações não concluídas são descartadas após um minuto.
+func (m *MemoryStorage) Scan(ctx context.Context, cursor uint64, match string, count int64) ([]string, uint64, error) {
+	if count <= 0 {
+		count = defaultScanCount
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	for id, snapshot := range m.snapshots {
+		if now.Sub(snapshot.createdAt) > scanSnapshotTTL {
+			delete(m.snapshots, id)
+		}
+	}
+
+	id, offset := uint32(cursor>>32), uint64(uint32(cursor))
+	if cursor == 0 {
+		pattern, err := globToRegexp(match)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		snapshot := &scanSnapshot{createdAt: now}
+		for key := range m.entries {
+			if _, ok := m.entry(key, now); ok && pattern.MatchString(key) {
+				snapshot.keys = append(snapshot.keys, key)
+			}
+		}
+		sort.Strings(snapshot.keys)
+
+		m.lastSnapshotID++
+		if m.lastSnapshotID == 0 {
+			m.lastSnapshotID++
+		}
+		id = m.lastSnapshotID
+		m.snapshots[id] = snapshot
+	}
+
+	snapshot, ok := m.snapshots[id]
+	if !ok || offset > uint64(len(snapshot.keys)) {
+		return nil, 0, fmt.Errorf("%w: %d", ErrInvalidCursor, cursor)
+	}
+
+	end := min(offset+uint64(count), uint64(len(snapshot.keys)))
+	keys := make([]string, 0, end-offset)
+	for _, key := range snapshot.keys[offset:end] {
+		if _, ok := m.entry(key, now); ok {
+			keys = append(keys, key)
+		}
+	}
+
+	if end == uint64(len(snapshot.keys)) {
+		delete(m.snapshots, id)
+		return keys, 0, nil
+	}
+	return keys, uint64(id)<<32 | end, nil
+}
+
+func (m *MemoryStorage) Close() error {
+	m.closeOnce.Do(func() {
+		close(m.done)
+	})
+	return nil
+}
+
+func expiresAt(now time.Time, expiration time.Duration) time.Time {
+	if expiration <= 0 {
+		return time.Time{}
+	}
+	return now.Add(expiration)
+}
+
+// globToRegexp converte um padrão glob no formato do Redis (*, ?, [abc],
+// [^abc], [a-z] e \ para escapar) em uma expressão regular. Padrão vazio
+// corresponde a todas as chaves.
+func globToRegexp(pattern string) (*regexp.Regexp, error) {
+	var expr strings.Builder
+	expr.WriteString("^")
+
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			expr.WriteString(".*")
+		case '?':
+			expr.WriteString(".")
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+			}
+			expr.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid scan pattern: %s", pattern)
+			}
+			class := pattern[i+1 : i+1+end]
+			negate := strings.HasPrefix(class, "^")
+			class = strings.TrimPrefix(class, "^")
+			expr.WriteString("[")
+			if negate {
+				expr.WriteString("^")
+			}
+			expr.WriteString(strings.NewReplacer(`\`, `\\`, "[", `\[`, "^", `\^`).Replace(class))
+			expr.WriteString("]")
+			i += end + 1
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	if pattern == "" {
+		expr.WriteString(".*")
+	}
+	expr.WriteString("$")
+
+	return regexp.Compile(expr.String())
+}
@@ -0,0 +1,133 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestMemoryStorage_Operations(t *testing.T) {
+	ctx := context.Background()
+	storage := NewMemoryStorage()
+	defer storage.Close()
+
+	for i := int64(1); i <= 3; i++ {
+		value, err := storage.Increment(ctx, "rate_limit:ip:192.168.1.1", time.Second)
+		if err != nil || value != i {
+			t.Fatalf("Increment() = %d, %v; expected %d", value, err, i)
+		}
+	}
+	if value, _ := storage.Get(ctx, "rate_limit:ip:192.168.1.1"); value != 3 {
+		t.Errorf("Get() = %d, expected 3", value)
+	}
+
+	storage.Set(ctx, "block:ip:192.168.1.1", 1, time.Minute)
+	if ttl, _ := storage.TTL(ctx, "block:ip:192.168.1.1"); ttl <= 59*time.Second || ttl > time.Minute {
+		t.Errorf("TTL() = %v, expected about 1m", ttl)
+	}
+
+	storage.Set(ctx, "block:ip:10.0.0.1", 1, 0)
+	if ttl, _ := storage.TTL(ctx, "block:ip:10.0.0.1"); ttl != 0 {
+		t.Errorf("TTL() without expiration = %v, expected 0", ttl)
+	}
+
+	storage.Delete(ctx, "block:ip:192.168.1.1")
+	if exists, _ := storage.Exists(ctx, "block:ip:192.168.1.1"); exists {
+		t.Error("Exists() should be false after Delete()")
+	}
+
+	storage.Set(ctx, "block:ip:10.0.0.2", 1, 10*time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	if exists, _ := storage.Exists(ctx, "block:ip:10.0.0.2"); exists {
+		t.Error("Exists() should be false after the key expires")
+	}
+	if value, _ := storage.Get(ctx, "block:ip:10.0.0.2"); value != 0 {
+		t.Errorf("Get() of expired key = %d, expected 0", value)
+	}
+}
+
+func TestMemoryStorage_Scan(t *testing.T) {
+	ctx := context.Background()
+	storage := NewMemoryStorage()
+	defer storage.Close()
+
+	var expected []string
+	for i := 0; i < 25; i++ {
+		key := fmt.Sprintf("block:token:%02d", i)
+		storage.Set(ctx, key, 1, time.Minute)
+		expected = append(expected, key)
+	}
+	storage.Set(ctx, "block:ip:2001:db8::/64", 1, time.Minute)
+	storage.Set(ctx, "rate_limit:token:00", 1, time.Second)
+
+	var keys []string
+	var cursor uint64
+	pages := 0
+	for {
+		page, next, err := storage.Scan(ctx, cursor, "block:token:*", 10)
+		if err != nil {
+			t.Fatalf("Scan() error = %v", err)
+		}
+		keys = append(keys, page...)
+		pages++
+
+		// Chaves removidas ou criadas durante a iteração
+		if pages == 1 {
+			storage.Delete(ctx, "block:token:24")
+			storage.Set(ctx, "block:token:99", 1, time.Minute)
+		}
+
+		if next == 0 {
+			break
+		}
+		cursor = next
+	}
+
+	expected = expected[:24]
+	sort.Strings(keys)
+	if pages != 3 || fmt.Sprint(keys) != fmt.Sprint(expected) {
+		t.Errorf("Scan() returned %d pages with %v, expected 3 pages with %v", pages, keys, expected)
+	}
+
+	tests := []struct {
+		match    string
+		expected int
+	}{
+		{match: "", expected: 27},
+		{match: "block:ip:*", expected: 1},
+		{match: "block:token:0?", expected: 10},
+		{match: "block:token:[12]*", expected: 14},
+		{match: "block:token:[^0-1]*", expected: 5},
+		{match: `block:token:\*`, expected: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.match, func(t *testing.T) {
+			keys, next, err := storage.Scan(ctx, 0, tt.match, 100)
+			if err != nil {
+				t.Fatalf("Scan() error = %v", err)
+			}
+			if len(keys) != tt.expected || next != 0 {
+				t.Errorf("Scan(%q) = %d keys (next %d), expected %d", tt.match, len(keys), next, tt.expected)
+			}
+		})
+	}
+
+	if _, _, err := storage.Scan(ctx, 12345<<32, "", 10); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("Scan() with an unknown cursor error = %v, expected %v", err, ErrInvalidCursor)
+	}
+
+	// Cursor de um snapshot válido com a posição além do fim
+	_, next, err := storage.Scan(ctx, 0, "block:token:*", 10)
+	if err != nil || next == 0 {
+		t.Fatalf("Scan() = next %d, err %v, expected more pages", next, err)
+	}
+	if _, _, err := storage.Scan(ctx, next&^0xffffffff|1000000, "", 10); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("Scan() with an out-of-range offset error = %v, expected %v", err, ErrInvalidCursor)
+	}
+	if _, _, err := storage.Scan(ctx, 0, "block:[", 10); err == nil {
+		t.Error("Scan() with an invalid pattern should return an error")
+	}
+}
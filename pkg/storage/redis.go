@@ -0,0 +1,135 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/m4rcelotoledo/rate-limiter/pkg/metrics"
+
+	"github.com/go-redis/redis/v8"
+)
+
+type RedisStorage struct {
+	client  *redis.Client
+	metrics *metrics.Metrics
+}
+
+// RedisOption configura componentes opcionais do RedisStorage
+type RedisOption func(*RedisStorage)
+
+// WithMetrics registra a latência e os erros de cada operação nas métricas
+// Prometheus informadas
+func WithMetrics(m *metrics.Metrics) RedisOption {
+	return func(r *RedisStorage) {
+		r.metrics = m
+	}
+}
+
+func NewRedisStorage(host, port, password string, db int, opts ...RedisOption) (*RedisStorage, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:     fmt.Sprintf("%s:%s", host, port),
+		Password: password,
+		DB:       db,
+	})
+
+	// Testa a conexão
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+	}
+
+	r := &RedisStorage{client: client}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r, nil
+}
+
+// observe registra a duração e o erro de uma operação; uso:
+// defer r.observe("get", time.Now(), &err)
+func (r *RedisStorage) observe(operation string, start time.Time, err *error) {
+	r.metrics.ObserveStorage(operation, time.Since(start), *err)
+}
+
+func (r *RedisStorage) Increment(ctx context.Context, key string, expiration time.Duration) (_ int64, err error) {
+	defer r.observe("increment", time.Now(), &err)
+
+	pipe := r.client.Pipeline()
+
+	// Incrementa o contador
+	incr := pipe.Incr(ctx, key)
+
+	// Define a expiração se a chave não existir
+	pipe.Expire(ctx, key, expiration)
+
+	_, err = pipe.Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return incr.Val(), nil
+}
+
+func (r *RedisStorage) Get(ctx context.Context, key string) (_ int64, err error) {
+	defer r.observe("get", time.Now(), &err)
+
+	val, err := r.client.Get(ctx, key).Int64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	return val, err
+}
+
+func (r *RedisStorage) Set(ctx context.Context, key string, value int64, expiration time.Duration) (err error) {
+	defer r.observe("set", time.Now(), &err)
+
+	return r.client.Set(ctx, key, value, expiration).Err()
+}
+
+func (r *RedisStorage) Exists(ctx context.Context, key string) (_ bool, err error) {
+	defer r.observe("exists", time.Now(), &err)
+
+	result, err := r.client.Exists(ctx, key).Result()
+	return result > 0, err
+}
+
+func (r *RedisStorage) Delete(ctx context.Context, key string) (err error) {
+	defer r.observe("delete", time.Now(), &err)
+
+	return r.client.Del(ctx, key).Err()
+}
+
+func (r *RedisStorage) TTL(ctx context.Context, key string) (_ time.Duration, err error) {
+	defer r.observe("ttl", time.Now(), &err)
+
+	ttl, err := r.client.PTTL(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	// PTTL retorna valores negativos para chaves inexistentes ou sem expiração
+	if ttl < 0 {
+		return 0, nil
+	}
+	return ttl, nil
+}
+
+// Scan usa o comando SCAN, que percorre as chaves de forma incremental sem
+// bloquear o Redis (ao contrário de KEYS)
+func (r *RedisStorage) Scan(ctx context.Context, cursor uint64, match string, count int64) (_ []string, _ uint64, err error) {
+	defer r.observe("scan", time.Now(), &err)
+
+	return r.client.Scan(ctx, cursor, match, count).Result()
+}
+
+// Client retorna o cliente Redis subjacente, para componentes que precisam
+// de comandos além da StorageStrategy (ex: key store em set do Redis)
+func (r *RedisStorage) Client() *redis.Client {
+	return r.client
+}
+
+func (r *RedisStorage) Close() error {
+	return r.client.Close()
+}
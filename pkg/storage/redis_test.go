@@ -0,0 +1,125 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func newTestRedisStorage(t *testing.T) (*RedisStorage, *miniredis.Miniredis) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	storage, err := NewRedisStorage(server.Host(), server.Port(), "", 0)
+	if err != nil {
+		t.Fatalf("NewRedisStorage() error = %v", err)
+	}
+	t.Cleanup(func() { storage.Close() })
+	return storage, server
+}
+
+func TestRedisStorage_Operations(t *testing.T) {
+	ctx := context.Background()
+	storage, server := newTestRedisStorage(t)
+
+	for i := int64(1); i <= 3; i++ {
+		value, err := storage.Increment(ctx, "rate_limit:ip:192.168.1.1", time.Second)
+		if err != nil || value != i {
+			t.Fatalf("Increment() = %d, %v; expected %d", value, err, i)
+		}
+	}
+	if value, _ := storage.Get(ctx, "rate_limit:ip:192.168.1.1"); value != 3 {
+		t.Errorf("Get() = %d, expected 3", value)
+	}
+	if value, err := storage.Get(ctx, "rate_limit:ip:10.0.0.9"); value != 0 || err != nil {
+		t.Errorf("Get() of missing key = %d, %v; expected 0, nil", value, err)
+	}
+
+	storage.Delete(ctx, "rate_limit:ip:192.168.1.1")
+	if exists, _ := storage.Exists(ctx, "rate_limit:ip:192.168.1.1"); exists {
+		t.Error("Exists() should be false after Delete()")
+	}
+
+	storage.Set(ctx, "block:ip:10.0.0.2", 1, 10*time.Second)
+	server.FastForward(11 * time.Second)
+	if exists, _ := storage.Exists(ctx, "block:ip:10.0.0.2"); exists {
+		t.Error("Exists() should be false after the key expires")
+	}
+}
+
+func TestRedisStorage_TTL(t *testing.T) {
+	ctx := context.Background()
+	storage, server := newTestRedisStorage(t)
+
+	storage.Set(ctx, "block:ip:192.168.1.1", 1, time.Minute)
+	storage.Set(ctx, "block:ip:10.0.0.1", 1, 0)
+
+	tests := []struct {
+		name     string
+		key      string
+		expected time.Duration
+	}{
+		{name: "Key with expiration", key: "block:ip:192.168.1.1", expected: time.Minute},
+		{name: "Key without expiration", key: "block:ip:10.0.0.1", expected: 0},
+		{name: "Missing key", key: "block:ip:10.0.0.9", expected: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ttl, err := storage.TTL(ctx, tt.key)
+			if err != nil {
+				t.Fatalf("TTL() error = %v", err)
+			}
+			if ttl != tt.expected {
+				t.Errorf("TTL() = %v, expected %v", ttl, tt.expected)
+			}
+		})
+	}
+
+	server.Close()
+	if _, err := storage.TTL(ctx, "block:ip:192.168.1.1"); err == nil {
+		t.Error("TTL() should return an error when Redis is unavailable")
+	}
+}
+
+func TestRedisStorage_Scan(t *testing.T) {
+	ctx := context.Background()
+	storage, _ := newTestRedisStorage(t)
+
+	var expected []string
+	for i := 0; i < 25; i++ {
+		key := fmt.Sprintf("block:token:%02d", i)
+		storage.Set(ctx, key, 1, time.Minute)
+		expected = append(expected, key)
+	}
+	storage.Set(ctx, "block:ip:2001:db8::/64", 1, time.Minute)
+	storage.Set(ctx, "rate_limit:token:00", 1, time.Second)
+
+	// COUNT é apenas uma sugestão do tamanho da página; o cursor deve ser
+	// seguido até voltar a 0
+	var keys []string
+	var cursor uint64
+	for {
+		page, next, err := storage.Scan(ctx, cursor, "block:token:*", 10)
+		if err != nil {
+			t.Fatalf("Scan() error = %v", err)
+		}
+		keys = append(keys, page...)
+		if next == 0 {
+			break
+		}
+		cursor = next
+	}
+
+	sort.Strings(keys)
+	if fmt.Sprint(keys) != fmt.Sprint(expected) {
+		t.Errorf("Scan() = %v, expected %v", keys, expected)
+	}
+
+	keys, next, err := storage.Scan(ctx, 0, "block:ip:*", 100)
+	if err != nil || next != 0 || fmt.Sprint(keys) != "[block:ip:2001:db8::/64]" {
+		t.Errorf("Scan(block:ip:*) = %v, %d, %v; expected only the IPv6 block", keys, next, err)
+	}
+}
@@ -0,0 +1,46 @@
+// Package storage contém a interface StorageStrategy e a implementação Redis
+// usadas pelo rate limiter.
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrInvalidCursor indica que o cursor de Scan não foi retornado por uma
+// iteração em andamento (ex: cursor alterado pelo cliente ou expirado)
+var ErrInvalidCursor = errors.New("invalid or expired scan cursor")
+
+// StorageStrategy define a interface para diferentes implementações de storage
+type StorageStrategy interface {
+	// Increment incrementa o contador de requisições para uma chave específica
+	Increment(ctx context.Context, key string, expiration time.Duration) (int64, error)
+
+	// Get retorna o valor atual do contador para uma chave específica
+	Get(ctx context.Context, key string) (int64, error)
+
+	// Set define um valor para uma chave com expiração
+	Set(ctx context.Context, key string, value int64, expiration time.Duration) error
+
+	// Exists verifica se uma chave existe
+	Exists(ctx context.Context, key string) (bool, error)
+
+	// Delete remove uma chave
+	Delete(ctx context.Context, key string) error
+
+	// TTL retorna o tempo restante até a expiração de uma chave. Retorna zero
+	// se a chave não existe ou não possui expiração.
+	TTL(ctx context.Context, key string) (time.Duration, error)
+
+	// Scan percorre as chaves que correspondem ao padrão glob match (como no
+	// SCAN do Redis: *, ? e [abc]), retornando cerca de count chaves por
+	// chamada e o cursor da próxima chamada. A iteração começa com cursor 0 e
+	// termina quando o cursor retornado é 0. Chaves presentes durante toda a
+	// iteração são retornadas ao menos uma vez; chaves criadas ou removidas
+	// durante a iteração podem ou não ser retornadas.
+	Scan(ctx context.Context, cursor uint64, match string, count int64) ([]string, uint64, error)
+
+	// Close fecha a conexão com o storage
+	Close() error
+}
@@ -0,0 +1,106 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/m4rcelotoledo/rate-limiter/pkg/storage"
+
+// TracedStorage envolve qualquer StorageStrategy criando um span
+// OpenTelemetry para cada operação
+type TracedStorage struct {
+	storage StorageStrategy
+	tracer  trace.Tracer
+}
+
+// NewTracedStorage instrumenta o storage com o tracer provider informado
+// (nil usa o provider global)
+func NewTracedStorage(storage StorageStrategy, provider trace.TracerProvider) *TracedStorage {
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+	return &TracedStorage{
+		storage: storage,
+		tracer:  provider.Tracer(instrumentationName),
+	}
+}
+
+func (t *TracedStorage) start(ctx context.Context, operation string) (context.Context, trace.Span) {
+	return t.tracer.Start(ctx, "rate_limiter.storage."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("rate_limiter.storage.operation", operation)),
+	)
+}
+
+func end(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func (t *TracedStorage) Increment(ctx context.Context, key string, expiration time.Duration) (int64, error) {
+	ctx, span := t.start(ctx, "increment")
+	value, err := t.storage.Increment(ctx, key, expiration)
+	span.SetAttributes(attribute.Int64("rate_limiter.storage.value", value))
+	end(span, err)
+	return value, err
+}
+
+func (t *TracedStorage) Get(ctx context.Context, key string) (int64, error) {
+	ctx, span := t.start(ctx, "get")
+	value, err := t.storage.Get(ctx, key)
+	end(span, err)
+	return value, err
+}
+
+func (t *TracedStorage) Set(ctx context.Context, key string, value int64, expiration time.Duration) error {
+	ctx, span := t.start(ctx, "set")
+	err := t.storage.Set(ctx, key, value, expiration)
+	end(span, err)
+	return err
+}
+
+func (t *TracedStorage) Exists(ctx context.Context, key string) (bool, error) {
+	ctx, span := t.start(ctx, "exists")
+	exists, err := t.storage.Exists(ctx, key)
+	span.SetAttributes(attribute.Bool("rate_limiter.storage.exists", exists))
+	end(span, err)
+	return exists, err
+}
+
+func (t *TracedStorage) Delete(ctx context.Context, key string) error {
+	ctx, span := t.start(ctx, "delete")
+	err := t.storage.Delete(ctx, key)
+	end(span, err)
+	return err
+}
+
+func (t *TracedStorage) TTL(ctx context.Context, key string) (time.Duration, error) {
+	ctx, span := t.start(ctx, "ttl")
+	ttl, err := t.storage.TTL(ctx, key)
+	end(span, err)
+	return ttl, err
+}
+
+func (t *TracedStorage) Scan(ctx context.Context, cursor uint64, match string, count int64) ([]string, uint64, error) {
+	ctx, span := t.start(ctx, "scan")
+	keys, next, err := t.storage.Scan(ctx, cursor, match, count)
+	span.SetAttributes(
+		attribute.String("rate_limiter.storage.match", match),
+		attribute.Int("rate_limiter.storage.keys", len(keys)),
+	)
+	end(span, err)
+	return keys, next, err
+}
+
+func (t *TracedStorage) Close() error {
+	return t.storage.Close()
+}
@@ -4,11 +4,12 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"net/netip"
 	"testing"
 
 	"github.com/m4rcelotoledo/rate-limiter/internal/config"
-	"github.com/m4rcelotoledo/rate-limiter/internal/limiter"
-	"github.com/m4rcelotoledo/rate-limiter/internal/storage"
+	"github.com/m4rcelotoledo/rate-limiter/pkg/limiter"
+	"github.com/m4rcelotoledo/rate-limiter/pkg/storage"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -164,7 +165,9 @@ func TestTokenExtraction(t *testing.T) {
 }
 
 func TestClientIPExtraction(t *testing.T) {
-	rateLimiter := &limiter.RateLimiter{}
+	rateLimiter := limiter.NewRateLimiter(storage.NewMemoryStorage(), &limiter.Config{
+		TrustedProxies: []netip.Prefix{netip.MustParsePrefix("127.0.0.0/8")},
+	})
 
 	t.Run("X-Forwarded-For Header", func(t *testing.T) {
 		req, _ := http.NewRequest("GET", "/", nil)
@@ -184,12 +187,29 @@ func TestClientIPExtraction(t *testing.T) {
 		assert.Equal(t, "10.0.0.1", ip)
 	})
 
+	t.Run("X-Forwarded-For From Untrusted Client", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/", nil)
+		req.Header.Set("X-Forwarded-For", "192.168.1.100")
+		req.RemoteAddr = "198.51.100.7:8080"
+
+		ip := rateLimiter.GetClientIP(req)
+		assert.Equal(t, "198.51.100.7", ip)
+	})
+
 	t.Run("RemoteAddr Fallback", func(t *testing.T) {
 		req, _ := http.NewRequest("GET", "/", nil)
 		req.RemoteAddr = "192.168.1.50:8080"
 
 		ip := rateLimiter.GetClientIP(req)
-		assert.Equal(t, "192.168.1.50:8080", ip)
+		assert.Equal(t, "192.168.1.50", ip)
+	})
+
+	t.Run("RemoteAddr IPv6 Fallback", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/", nil)
+		req.RemoteAddr = "[2001:DB8::1]:8080"
+
+		ip := rateLimiter.GetClientIP(req)
+		assert.Equal(t, "2001:db8::1", ip)
 	})
 }
 